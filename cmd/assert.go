@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apcera/termtables"
+	"github.com/spf13/cobra"
+)
+
+var assertions []assertion
+
+var assertionOperators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// assertion is the rule for the metric of result, like `p99<8s`.
+type assertion struct {
+	Rule     string     `json:"rule"`
+	Metric   string     `json:"metric"`
+	Kind     metricKind `json:"kind"`
+	Operator string     `json:"operator"`
+	Expected float64    `json:"expected"`
+}
+
+func parseAssertion(s string) (a assertion, err error) {
+	a.Rule = strings.TrimSpace(s)
+
+	for _, o := range assertionOperators {
+		i := strings.Index(a.Rule, o)
+		if i < 0 {
+			continue
+		}
+
+		a.Metric = strings.TrimSpace(a.Rule[:i])
+		a.Operator = o
+
+		var found bool
+		if a.Kind, found = metricKindOf(a.Metric); !found {
+			err = fmt.Errorf("%v: '%s'", errUnknownMetric, a.Rule)
+			return
+		}
+
+		if a.Expected, err = parseMetricValue(a.Kind, a.Rule[i+len(o):]); err != nil {
+			err = fmt.Errorf("invalid value: '%s'; %v", a.Rule, err)
+		}
+
+		return
+	}
+
+	err = fmt.Errorf("'<metric><operator><value>' is expected: '%s'", a.Rule)
+
+	return
+}
+
+// Check returns true, if the actual value satisfies the assertion.
+func (a assertion) Check(actual float64) bool {
+	switch a.Operator {
+	case "<":
+		return actual < a.Expected
+	case "<=":
+		return actual <= a.Expected
+	case ">":
+		return actual > a.Expected
+	case ">=":
+		return actual >= a.Expected
+	case "==":
+		return actual == a.Expected
+	case "!=":
+		return actual != a.Expected
+	default:
+		return false
+	}
+}
+
+// loadAssertFile reads the rules from file; one rule in one line and the line
+// starting with `#` is ignored.
+func loadAssertFile(f string) (rules []string, err error) {
+	var r *os.File
+	if r, err = os.Open(f); err != nil {
+		return
+	}
+	defer r.Close()
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		if len(l) < 1 || strings.HasPrefix(l, "#") {
+			continue
+		}
+		rules = append(rules, l)
+	}
+
+	err = sc.Err()
+
+	return
+}
+
+func parseAssertFlags(cmd *cobra.Command) {
+	rules := flagAsserts
+	if len(flagAssertFile) > 0 {
+		fileRules, err := loadAssertFile(flagAssertFile)
+		if err != nil {
+			printFlagsError(cmd, "--assert-file", err)
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	for _, s := range rules {
+		a, err := parseAssertion(s)
+		if err != nil {
+			printFlagsError(cmd, "--assert", err)
+		}
+		assertions = append(assertions, a)
+	}
+}
+
+type assertionResult struct {
+	assertion
+	Actual float64 `json:"actual"`
+	NoData bool    `json:"no-data,omitempty"`
+	Passed bool    `json:"passed"`
+}
+
+func (r assertionResult) ActualString() string {
+	if r.NoData {
+		return "no data"
+	}
+
+	return formatMetricValue(r.Kind, r.Actual)
+}
+
+// evaluateAssertions checks the assertions against the metrics of report;
+// the counter of errors, which is not found in the report, like
+// `sebak_error.<code>`, is regarded as 0, but the metric without data, like
+// p99 without confirmed payments, fails. The unknown error type or category
+// is already rejected by parseAssertion.
+func evaluateAssertions(report *resultReport) (results []assertionResult, failed int) {
+	metrics := map[string]resultMetric{}
+	for _, m := range report.Metrics() {
+		metrics[m.Name] = m
+	}
+
+	for _, a := range assertions {
+		r := assertionResult{assertion: a}
+		if m, found := metrics[a.Metric]; found {
+			r.Actual, r.NoData = m.Value, m.NoData
+		} else {
+			r.NoData = !isCounterMetric(a.Metric)
+		}
+		r.Passed = !r.NoData && a.Check(r.Actual)
+		if !r.Passed {
+			failed++
+		}
+		results = append(results, r)
+	}
+
+	return
+}
+
+func printAssertionResults(w io.Writer, results []assertionResult) {
+	table := termtables.CreateTable()
+	table.AddHeaders("assertion", "actual", "result")
+	for _, r := range results {
+		result := "PASS"
+		if !r.Passed {
+			result = "FAIL"
+		}
+		table.AddRow(r.Rule, r.ActualString(), result)
+	}
+
+	fmt.Fprint(w, table.Render())
+}
+
+// checkAssertions prints the results of assertions and exits with
+// exitCodeAssertion if any of them failed. Except the terminal format, the
+// results are printed to stderr not to break the output.
+func checkAssertions(report *resultReport) {
+	if len(assertions) < 1 {
+		return
+	}
+
+	var w io.Writer = os.Stdout
+	if flagFormat != "terminal" {
+		w = os.Stderr
+	}
+
+	results, failed := evaluateAssertions(report)
+
+	fmt.Fprintln(w)
+	printAssertionResults(w, results)
+
+	if failed > 0 {
+		fmt.Fprintf(w, "%d of %d assertions failed\n", failed, len(results))
+		os.Exit(exitCodeAssertion)
+	}
+}
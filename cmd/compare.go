@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/apcera/termtables"
+	"github.com/spf13/cobra"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+var (
+	compareCmd  *cobra.Command
+	thresholds  []compareThreshold
+	compareLogs [2]string
+)
+
+func init() {
+	compareCmd = &cobra.Command{
+		Use:   "compare <baseline log> <candidate log>",
+		Short: "Compare 2 results",
+		Run: func(c *cobra.Command, args []string) {
+			parseCompareFlags(args)
+
+			runCompare()
+		},
+	}
+
+	compareCmd.Flags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "log level, {crit, error, warn, info, debug}")
+	compareCmd.Flags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
+	compareCmd.Flags().StringVar(&flagLog, "log", flagLog, "set log file")
+	compareCmd.Flags().StringArrayVar(&flagThresholds, "threshold", flagThresholds, "allowed regression of metric, '<metric>=<relative>%' or '<metric>=+<absolute>', 'p99=10%', 'error_rate=+0.1%'")
+	compareCmd.Flags().StringVar(&flagFormat, "format", flagFormat, "output format, {terminal, json, markdown}")
+	addResultFilterFlags(compareCmd)
+	compareCmd.Flags().StringVar(&flagErrorCatalogue, "error-catalogue", flagErrorCatalogue, "JSON file of SEBAK errors, which are added to the error catalogue")
+
+	rootCmd.AddCommand(compareCmd)
+}
+
+func parseCompareFlags(args []string) {
+	setLogging()
+
+	if len(args) < 2 {
+		printError(compareCmd, fmt.Errorf("<baseline log> and <candidate log> are missing"))
+	}
+	compareLogs = [2]string{args[0], args[1]}
+
+	for _, s := range flagThresholds {
+		t, err := parseCompareThreshold(s)
+		if err != nil {
+			printFlagsError(compareCmd, "--threshold", err)
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	parseErrorCatalogueFlag(compareCmd)
+	parseResultFilterFlags(compareCmd)
+
+	switch flagFormat {
+	case "terminal", "json", "markdown":
+	default:
+		printFlagsError(compareCmd, "--format", fmt.Errorf("'%s'", flagFormat))
+	}
+
+	parsedFlags := []interface{}{}
+	parsedFlags = append(parsedFlags, "\n\tbaseline-log", compareLogs[0])
+	parsedFlags = append(parsedFlags, "\n\tcandidate-log", compareLogs[1])
+	parsedFlags = append(parsedFlags, "\n\tthreshold", flagThresholds)
+	parsedFlags = append(parsedFlags, "\n\tformat", flagFormat)
+	parsedFlags = append(parsedFlags, "\n\terror-catalogue", flagErrorCatalogue)
+	parsedFlags = append(parsedFlags, "\n\tsince", flagSince)
+	parsedFlags = append(parsedFlags, "\n\tuntil", flagUntil)
+	parsedFlags = append(parsedFlags, "\n\tskip-warmup", flagSkipWarmup)
+	parsedFlags = append(parsedFlags, "\n\tskip-cooldown", flagSkipCooldown)
+	parsedFlags = append(parsedFlags, "\n\tphase", flagPhase)
+	parsedFlags = append(parsedFlags, "\n\tlog-level", flagLogLevel)
+	parsedFlags = append(parsedFlags, "\n\tlog-format", flagLogFormat)
+	parsedFlags = append(parsedFlags, "\n\tlog", flagLog)
+	parsedFlags = append(parsedFlags, "\n", "")
+
+	log.Debug("parsed flags:", parsedFlags...)
+}
+
+// compareThreshold is the allowed regression of the metrics, which match to
+// Pattern; Pattern can have the wildcard, like `error_rate.*`.
+type compareThreshold struct {
+	Pattern  string
+	Relative bool
+	Limit    string
+}
+
+func (t compareThreshold) String() string {
+	if t.Relative {
+		return t.Limit
+	}
+
+	return "+" + t.Limit
+}
+
+func parseCompareThreshold(s string) (t compareThreshold, err error) {
+	i := strings.Index(s, "=")
+	if i < 1 {
+		err = fmt.Errorf("'<metric>=<limit>' is expected: '%s'", s)
+		return
+	}
+
+	t.Pattern = strings.TrimSpace(s[:i])
+	if _, err = path.Match(t.Pattern, ""); err != nil {
+		err = fmt.Errorf("invalid metric pattern: '%s'; %v", s, err)
+		return
+	} else if !strings.ContainsAny(t.Pattern, "*?[") && !isMetricName(t.Pattern) {
+		err = fmt.Errorf("%v: '%s'", errUnknownMetric, t.Pattern)
+		return
+	}
+
+	limit := strings.TrimSpace(s[i+1:])
+	switch {
+	case strings.HasPrefix(limit, "+"):
+		t.Limit = strings.TrimPrefix(limit, "+")
+	case strings.HasSuffix(limit, "%"):
+		t.Relative = true
+		t.Limit = limit
+		if _, err = parseMetricValue(metricRatio, limit); err != nil {
+			err = fmt.Errorf("invalid relative limit: '%s'; %v", s, err)
+			return
+		}
+	default:
+		err = fmt.Errorf("limit must be '<relative>%%' or '+<absolute>': '%s'", s)
+	}
+
+	return
+}
+
+func (t compareThreshold) Match(name string) bool {
+	matched, _ := path.Match(t.Pattern, name)
+	return matched
+}
+
+// Check returns true, if the regression of metric is over the limit.
+func (t compareThreshold) Check(m comparedMetric) (regressed bool, err error) {
+	worse := m.Delta
+	if m.HigherIsBetter {
+		worse = -worse
+	}
+	if worse <= 0 {
+		return false, nil
+	}
+
+	if !t.Relative {
+		var limit float64
+		if limit, err = parseMetricValue(m.Kind, t.Limit); err != nil {
+			return
+		}
+		return worse > limit, nil
+	}
+
+	limit, _ := parseMetricValue(metricRatio, t.Limit)
+	if m.Baseline == 0 {
+		return true, nil
+	}
+
+	return worse/math.Abs(m.Baseline) > limit, nil
+}
+
+type comparedMetric struct {
+	Name           string     `json:"name"`
+	Kind           metricKind `json:"kind"`
+	Baseline       float64    `json:"baseline"`
+	Candidate      float64    `json:"candidate"`
+	Delta          float64    `json:"delta"`
+	DeltaRatio     *float64   `json:"delta-ratio"` // NOTE nil if baseline is 0
+	HigherIsBetter bool       `json:"higher-is-better"`
+	Threshold      string     `json:"threshold,omitempty"`
+	Regressed      bool       `json:"regressed"`
+}
+
+func (m comparedMetric) formatDelta() string {
+	sign := "+"
+	if m.Delta < 0 {
+		sign = "-"
+	}
+
+	return sign + formatMetricValue(m.Kind, math.Abs(m.Delta))
+}
+
+func (m comparedMetric) formatDeltaRatio() string {
+	if m.DeltaRatio == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%+.2f％", *m.DeltaRatio*100)
+}
+
+func (m comparedMetric) status() string {
+	if len(m.Threshold) < 1 {
+		return ""
+	} else if m.Regressed {
+		return "REGRESSED"
+	}
+
+	return "ok"
+}
+
+// compareMetrics lines up the metrics of baseline and candidate; the metric,
+// which is found only in one side, like `sebak_error.<code>`, is regarded as
+// 0 in the other side.
+func compareMetrics(baseline, candidate []resultMetric) (compared []comparedMetric, err error) {
+	candidates := map[string]resultMetric{}
+	for _, m := range candidate {
+		candidates[m.Name] = m
+	}
+
+	var merged []resultMetric
+	found := map[string]bool{}
+	for _, m := range baseline {
+		merged = append(merged, m)
+		found[m.Name] = true
+	}
+	var onlyCandidate []resultMetric
+	for _, m := range candidate {
+		if !found[m.Name] {
+			onlyCandidate = append(onlyCandidate, m)
+		}
+	}
+	sortMetrics(onlyCandidate)
+	merged = append(merged, onlyCandidate...)
+
+	for _, m := range merged {
+		c := comparedMetric{
+			Name:           m.Name,
+			Kind:           m.Kind,
+			HigherIsBetter: m.HigherIsBetter,
+		}
+		if found[m.Name] {
+			c.Baseline = m.Value
+		}
+		if cm, ok := candidates[m.Name]; ok {
+			c.Candidate = cm.Value
+		}
+		c.Delta = c.Candidate - c.Baseline
+		if c.Baseline != 0 {
+			ratio := c.Delta / math.Abs(c.Baseline)
+			c.DeltaRatio = &ratio
+		}
+
+		for _, t := range thresholds {
+			if !t.Match(c.Name) {
+				continue
+			}
+			c.Threshold = t.String()
+			if c.Regressed, err = t.Check(c); err != nil {
+				err = fmt.Errorf("failed to check threshold of '%s'; %v", c.Name, err)
+				return
+			}
+			break
+		}
+
+		compared = append(compared, c)
+	}
+
+	return
+}
+
+type comparison struct {
+	Baseline  string           `json:"baseline"`
+	Candidate string           `json:"candidate"`
+	Config    [][3]string      `json:"config"`
+	Metrics   []comparedMetric `json:"metrics"`
+	Regressed []string         `json:"regressed"`
+}
+
+func loadResultFile(f string) (rl analysis.Log, err error) {
+	var r *os.File
+	if r, err = os.Open(f); err != nil {
+		return
+	}
+	defer r.Close()
+
+	rl, err = analysis.Load(r)
+	rl.Source = f
+	if err == nil {
+		rl, err = analysis.FilterLog(rl, filter)
+	}
+	if err != nil {
+		err = fmt.Errorf("%s: %v", f, err)
+	}
+
+	return
+}
+
+func newComparison(baselineFile, candidateFile string) (c *comparison, err error) {
+	var baseline, candidate analysis.Log
+	if baseline, err = loadResultFile(baselineFile); err != nil {
+		return
+	}
+	if candidate, err = loadResultFile(candidateFile); err != nil {
+		return
+	}
+
+	if baseline.Config.Node.Policy.NetworkID != candidate.Config.Node.Policy.NetworkID {
+		log.Warn(
+			"network id is different",
+			"baseline", baseline.Config.Node.Policy.NetworkID,
+			"candidate", candidate.Config.Node.Policy.NetworkID,
+		)
+	}
+
+	c = &comparison{
+		Baseline:  baselineFile,
+		Candidate: candidateFile,
+		Config: [][3]string{
+			{"testing time", baseline.Config.Timeout.String(), candidate.Config.Timeout.String()},
+			{"concurrent requests", fmt.Sprintf("%d", baseline.Config.T), fmt.Sprintf("%d", candidate.Config.T)},
+			{"operations", fmt.Sprintf("%d", baseline.Config.Operations), fmt.Sprintf("%d", candidate.Config.Operations)},
+			{"network id", baseline.Config.Node.Policy.NetworkID, candidate.Config.Node.Policy.NetworkID},
+		},
+	}
+
+	if c.Metrics, err = compareMetrics(
+		newResultReport(baseline).Metrics(),
+		newResultReport(candidate).Metrics(),
+	); err != nil {
+		return
+	}
+
+	for _, m := range c.Metrics {
+		if m.Regressed {
+			c.Regressed = append(c.Regressed, m.Name)
+		}
+	}
+
+	return
+}
+
+func (c *comparison) Render(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return c.renderJSON(w)
+	case "markdown":
+		return c.renderMarkdown(w)
+	default:
+		return c.renderTerminal(w)
+	}
+}
+
+func (c *comparison) renderTerminal(w io.Writer) error {
+	table := termtables.CreateTable()
+	table.AddHeaders("", "baseline", "candidate")
+	table.AddRow("file", c.Baseline, c.Candidate)
+	for _, i := range c.Config {
+		table.AddRow(i[0], i[1], i[2])
+	}
+	fmt.Fprint(w, table.Render())
+	fmt.Fprintln(w)
+
+	table = termtables.CreateTable()
+	table.AddHeaders("metric", "baseline", "candidate", "delta", "delta %", "threshold", "status")
+	for _, m := range c.Metrics {
+		table.AddRow(
+			m.Name,
+			formatMetricValue(m.Kind, m.Baseline),
+			formatMetricValue(m.Kind, m.Candidate),
+			m.formatDelta(),
+			m.formatDeltaRatio(),
+			m.Threshold,
+			m.status(),
+		)
+	}
+	fmt.Fprint(w, table.Render())
+
+	if len(c.Regressed) > 0 {
+		fmt.Fprintf(w, "regressed: %s\n", strings.Join(c.Regressed, ", "))
+	}
+
+	return nil
+}
+
+func (c *comparison) renderJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
+}
+
+func (c *comparison) renderMarkdown(w io.Writer) error {
+	fmt.Fprintln(w, "# sebak-hot-body comparison")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| | baseline | candidate |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	fmt.Fprintf(w, "| file | %s | %s |\n", c.Baseline, c.Candidate)
+	for _, i := range c.Config {
+		fmt.Fprintf(w, "| %s | %s | %s |\n", i[0], i[1], i[2])
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| metric | baseline | candidate | delta | delta % | threshold | status |")
+	fmt.Fprintln(w, "| --- | ---: | ---: | ---: | ---: | ---: | --- |")
+	for _, m := range c.Metrics {
+		fmt.Fprintf(
+			w,
+			"| %s | %s | %s | %s | %s | %s | %s |\n",
+			m.Name,
+			formatMetricValue(m.Kind, m.Baseline),
+			formatMetricValue(m.Kind, m.Candidate),
+			m.formatDelta(),
+			m.formatDeltaRatio(),
+			m.Threshold,
+			m.status(),
+		)
+	}
+
+	return nil
+}
+
+func runCompare() {
+	c, err := newComparison(compareLogs[0], compareLogs[1])
+	if err != nil {
+		printError(compareCmd, err)
+	}
+
+	if err = c.Render(os.Stdout, flagFormat); err != nil {
+		printError(compareCmd, err)
+	}
+
+	if len(c.Regressed) > 0 {
+		os.Exit(exitCodeRegression)
+	}
+
+	os.Exit(0)
+}
@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultConfigFile string = ".sebak-hot-body.yaml" // NOTE in home directory
+	envPrefix         string = "SEBAK_HOT_BODY_"
+)
+
+// configSource is where the value of flag comes from; the flag has the
+// highest priority and the default has the lowest.
+type configSource string
+
+const (
+	configSourceFlag    configSource = "flag"
+	configSourceEnv     configSource = "env"
+	configSourceProfile configSource = "profile"
+	configSourceDefault configSource = "default"
+)
+
+// configFlags are the flags of config file itself, they are not set by config
+// file.
+var configFlags = map[string]bool{
+	"config":       true,
+	"profile":      true,
+	"print-config": true,
+	"help":         true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagConfig, "config", flagConfig, "config file; if empty, '~/"+defaultConfigFile+"'")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", flagProfile, "profile of config file; if empty, 'profile' of config file")
+	rootCmd.PersistentFlags().BoolVar(&flagPrintConfig, "print-config", flagPrintConfig, "print the effective config and exit")
+
+	rootCmd.PersistentPreRun = func(c *cobra.Command, args []string) {
+		sources, err := applyConfig(c)
+		if err != nil {
+			printError(c, err)
+		}
+
+		if flagPrintConfig {
+			printConfig(c, sources, os.Stdout)
+			os.Exit(0)
+		}
+	}
+}
+
+// envName returns the name of environment variable of flag, `log-level`
+// becomes `SEBAK_HOT_BODY_LOG_LEVEL`.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// configFile is the loaded config file. The top level keys are the values of
+// flags, which are applied to every profile, `profile` is the default profile
+// and `profiles` has the values of flags by profile name.
+type configFile struct {
+	Path     string
+	Profile  string
+	Common   map[string]interface{}
+	Profiles map[string]map[string]interface{}
+}
+
+func loadConfigFile(f string) (config configFile, err error) {
+	config.Path = f
+
+	var r *os.File
+	if r, err = os.Open(f); err != nil {
+		return
+	}
+	defer r.Close()
+
+	var m map[string]interface{}
+	if m, err = parseConfigYAML(r); err != nil {
+		err = fmt.Errorf("failed to parse config file, '%s'; %v", f, err)
+		return
+	}
+
+	config.Common = map[string]interface{}{}
+	config.Profiles = map[string]map[string]interface{}{}
+	for k, v := range m {
+		switch k {
+		case "profile":
+			s, ok := v.(string)
+			if !ok {
+				err = fmt.Errorf("invalid 'profile' of config file, '%s'", f)
+				return
+			}
+			config.Profile = s
+		case "profiles":
+			profiles, ok := v.(map[string]interface{})
+			if !ok {
+				err = fmt.Errorf("invalid 'profiles' of config file, '%s'", f)
+				return
+			}
+			for name, p := range profiles {
+				values, ok := p.(map[string]interface{})
+				if !ok {
+					err = fmt.Errorf("invalid profile, '%s' of config file, '%s'", name, f)
+					return
+				}
+				config.Profiles[name] = values
+			}
+		default:
+			config.Common[k] = v
+		}
+	}
+
+	return
+}
+
+// configFilePath returns the config file; `--config` or
+// `SEBAK_HOT_BODY_CONFIG`. Without them, the default config file is used only
+// if it exists.
+func configFilePath(c *cobra.Command) (f string, required bool) {
+	if c.Flags().Changed("config") {
+		return flagConfig, true
+	}
+	if v, found := os.LookupEnv(envName("config")); found && len(v) > 0 {
+		return v, true
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return "", false
+	}
+
+	return filepath.Join(u.HomeDir, defaultConfigFile), false
+}
+
+// applyConfig sets the flags of command, which are not given, by environment
+// variable and profile of config file.
+func applyConfig(c *cobra.Command) (sources map[string]configSource, err error) {
+	var config configFile
+	if f, required := configFilePath(c); len(f) > 0 {
+		if config, err = loadConfigFile(f); os.IsNotExist(err) && !required {
+			err = nil
+		} else if err != nil {
+			return
+		}
+	}
+
+	profile := config.Profile
+	if v, found := os.LookupEnv(envName("profile")); found && len(v) > 0 {
+		profile = v
+	}
+	if c.Flags().Changed("profile") {
+		profile = flagProfile
+	}
+
+	values := map[string]interface{}{}
+	for k, v := range config.Common {
+		values[k] = v
+	}
+	if len(profile) > 0 {
+		p, found := config.Profiles[profile]
+		if !found {
+			err = fmt.Errorf("profile, '%s' not found in config file, '%s'", profile, config.Path)
+			return
+		}
+		for k, v := range p {
+			values[k] = v
+		}
+	}
+	flagProfile = profile
+
+	for k := range values {
+		if configFlags[k] || !isKnownFlag(k) {
+			err = fmt.Errorf("unknown key, '%s' in config file, '%s'", k, config.Path)
+			return
+		}
+	}
+
+	sources = map[string]configSource{}
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || configFlags[f.Name] {
+			return
+		}
+
+		switch {
+		case f.Changed:
+			sources[f.Name] = configSourceFlag
+		case setFlagFromEnv(c, f, &err):
+			sources[f.Name] = configSourceEnv
+		case setFlagFromProfile(c, f, values, &err):
+			sources[f.Name] = configSourceProfile
+		default:
+			sources[f.Name] = configSourceDefault
+		}
+	})
+
+	return
+}
+
+// isKnownFlag checks whether the key of config file is the flag of any
+// command including the nested subcommands; the flags of the other commands
+// are ignored.
+func isKnownFlag(name string) bool {
+	return hasFlag(rootCmd, name)
+}
+
+func hasFlag(c *cobra.Command, name string) bool {
+	if c.Flags().Lookup(name) != nil {
+		return true
+	}
+
+	for _, sub := range c.Commands() {
+		if hasFlag(sub, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isArrayFlag(f *pflag.Flag) bool {
+	return strings.HasSuffix(f.Value.Type(), "Array") || strings.HasSuffix(f.Value.Type(), "Slice")
+}
+
+func setFlag(c *cobra.Command, f *pflag.Flag, values []string, source string) (err error) {
+	for _, v := range values {
+		if err = c.Flags().Set(f.Name, v); err != nil {
+			return fmt.Errorf("invalid '%s' of %s; %v", f.Name, source, err)
+		}
+	}
+
+	return
+}
+
+// setFlagFromEnv sets the flag by it's environment variable; the values of
+// array flag, like `--assert`, are separated by comma.
+func setFlagFromEnv(c *cobra.Command, f *pflag.Flag, err *error) bool {
+	name := envName(f.Name)
+	v, found := os.LookupEnv(name)
+	if !found {
+		return false
+	}
+
+	values := []string{v}
+	if isArrayFlag(f) {
+		values = nil
+		for _, i := range strings.Split(v, ",") {
+			if i = strings.TrimSpace(i); len(i) > 0 {
+				values = append(values, i)
+			}
+		}
+	}
+
+	*err = setFlag(c, f, values, name)
+
+	return true
+}
+
+func setFlagFromProfile(c *cobra.Command, f *pflag.Flag, values map[string]interface{}, err *error) bool {
+	v, found := values[f.Name]
+	if !found {
+		return false
+	}
+
+	var l []string
+	switch t := v.(type) {
+	case string:
+		l = []string{t}
+	case []string:
+		if !isArrayFlag(f) {
+			*err = fmt.Errorf("invalid '%s' of profile; list is not allowed", f.Name)
+			return true
+		}
+		l = t
+	default:
+		*err = fmt.Errorf("invalid '%s' of profile; %T", f.Name, v)
+		return true
+	}
+
+	*err = setFlag(c, f, l, "profile")
+
+	return true
+}
+
+// printConfig prints the effective flags of command in the format of config
+// file with where they come from.
+func printConfig(c *cobra.Command, sources map[string]configSource, w io.Writer) {
+	fmt.Fprintf(w, "# %s\n", c.CommandPath())
+	if len(flagProfile) > 0 {
+		fmt.Fprintf(w, "# profile: %s\n", flagProfile)
+	}
+
+	var names []string
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := c.Flags().Lookup(name)
+		source := fmt.Sprintf("# %s", sources[name])
+
+		if isArrayFlag(f) {
+			values, err := getArrayFlag(c, f)
+			if err != nil {
+				fmt.Fprintf(w, "%s: %s # %v\n", name, quoteConfigValue(f.Value.String()), err)
+				continue
+			}
+			if len(values) < 1 {
+				fmt.Fprintf(w, "%s: [] %s\n", name, source)
+				continue
+			}
+			fmt.Fprintf(w, "%s: %s\n", name, source)
+			for _, v := range values {
+				fmt.Fprintf(w, "  - %s\n", quoteConfigValue(v))
+			}
+			continue
+		}
+
+		fmt.Fprintf(w, "%s: %s %s\n", name, quoteConfigValue(f.Value.String()), source)
+	}
+}
+
+// getArrayFlag returns the values of array flag by it's type.
+func getArrayFlag(c *cobra.Command, f *pflag.Flag) (values []string, err error) {
+	switch f.Value.Type() {
+	case "stringArray":
+		return c.Flags().GetStringArray(f.Name)
+	case "stringSlice":
+		return c.Flags().GetStringSlice(f.Name)
+	}
+
+	return nil, fmt.Errorf("unknown array flag type, '%s'", f.Value.Type())
+}
+
+func quoteConfigValue(s string) string {
+	if len(s) < 1 || strings.ContainsAny(s, "#:'\"[]{},&*!|>%@`") || strings.TrimSpace(s) != s {
+		return "'" + strings.Replace(s, "'", "''", -1) + "'"
+	}
+
+	return s
+}
+
+// parseConfigYAML parses the config file; the nested mappings become
+// `map[string]interface{}`, the lists become `[]string` and the scalars
+// become string.
+func parseConfigYAML(r io.Reader) (m map[string]interface{}, err error) {
+	var b []byte
+	if b, err = ioutil.ReadAll(r); err != nil {
+		return
+	}
+
+	var raw map[string]interface{}
+	if err = yaml.UnmarshalStrict(b, &raw); err != nil {
+		return
+	}
+
+	m = map[string]interface{}{}
+	for k, v := range raw {
+		if m[k], err = normalizeConfigValue(v); err != nil {
+			return nil, fmt.Errorf("'%s': %v", k, err)
+		}
+	}
+
+	return
+}
+
+// normalizeConfigValue converts the decoded YAML value into the types of
+// config file.
+func normalizeConfigValue(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(t), nil
+	case []interface{}:
+		l := []string{}
+		for _, i := range t {
+			s, err := normalizeConfigValue(i)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := s.(string); !ok {
+				return nil, errors.New("only scalar is allowed in list")
+			}
+			l = append(l, s.(string))
+		}
+		return l, nil
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, i := range t {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("key must be string, %v", k)
+			}
+
+			var err error
+			if m[key], err = normalizeConfigValue(i); err != nil {
+				return nil, fmt.Errorf("'%s': %v", key, err)
+			}
+		}
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("not supported value, %T", v)
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+const (
+	dashboardWindow   time.Duration = 10 * time.Second
+	dashboardInterval time.Duration = 1 * time.Second
+)
+
+type dashboardSample struct {
+	time       time.Time
+	elapsed    time.Duration
+	operations int
+	failed     bool
+}
+
+// dashboard collects the records of running hotter thru the listener of
+// `hotbody.Result` and renders them in the full-screen terminal.
+type dashboard struct {
+	sync.RWMutex
+	hotter      *hotbody.Hotter
+	output      io.Writer
+	samples     []dashboardSample
+	requests    int
+	operations  int
+	errors      int
+	errorTypes  map[hotbody.RecordErrorType]int
+	sebakErrors map[hotbody.RecordErrorType]int
+}
+
+func newDashboard(hotter *hotbody.Hotter, output io.Writer) *dashboard {
+	d := &dashboard{
+		hotter:      hotter,
+		output:      output,
+		errorTypes:  map[hotbody.RecordErrorType]int{},
+		sebakErrors: map[hotbody.RecordErrorType]int{},
+	}
+
+	hotter.Result().AddListener(d.receive)
+
+	return d
+}
+
+func (d *dashboard) receive(b []byte) {
+	record, err := hotbody.DecodeRecord(b)
+	if err != nil || record == nil {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	switch record.GetType() {
+	case "payment":
+		r := record.(hotbody.RecordPayment)
+		sample := dashboardSample{
+			time:       r.GetTime(),
+			elapsed:    time.Duration(r.GetElapsed()),
+			operations: int(r.Count),
+			failed:     r.GetError() != nil,
+		}
+		d.samples = append(d.samples, sample)
+		d.requests++
+		if sample.failed {
+			d.errors++
+			d.errorTypes[r.GetErrorType()]++
+		} else {
+			d.operations += sample.operations
+		}
+	case "sebak-error":
+		d.sebakErrors[record.GetErrorType()]++
+	}
+}
+
+// run renders the dashboard until done is closed.
+func (d *dashboard) run(done chan bool) {
+	fmt.Fprint(d.output, "\x1b[?1049h\x1b[?25l") // NOTE alternate screen and hide cursor
+	defer fmt.Fprint(d.output, "\x1b[?25h\x1b[?1049l")
+
+	for {
+		d.render()
+
+		select {
+		case <-done:
+			return
+		case <-time.After(dashboardInterval):
+		}
+	}
+}
+
+func (d *dashboard) render() {
+	status := d.hotter.Status()
+	now := time.Now()
+
+	d.Lock()
+	// NOTE drop the samples out of sliding window
+	var i int
+	for i = 0; i < len(d.samples); i++ {
+		if now.Sub(d.samples[i].time) <= dashboardWindow {
+			break
+		}
+	}
+	d.samples = d.samples[i:]
+
+	var windowConfirmed, windowOperations int
+	var latencies []time.Duration
+	for _, s := range d.samples {
+		latencies = append(latencies, s.elapsed)
+		if !s.failed {
+			windowConfirmed++
+			windowOperations += s.operations
+		}
+	}
+
+	requests, operations, countError := d.requests, d.operations, d.errors
+	errorTypes := map[string]int{}
+	for k, v := range d.errorTypes {
+		errorTypes[string(k)] = v
+	}
+	for k, v := range d.sebakErrors {
+		errorTypes["sebak-error: "+string(k)] += v
+	}
+	d.Unlock()
+
+	var lines []string
+	line := func(format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
+	}
+	head := func(s string) {
+		line("")
+		line("\x1b[1m* %s\x1b[0m", s)
+	}
+
+	line("\x1b[1msebak-hot-body\x1b[0m  %s", FormatISO8601(now))
+
+	head("time")
+	if status.Started.IsZero() {
+		line("  %-22s %s", "state", "preparing accounts")
+	} else {
+		elapsed := now.Sub(status.Started)
+		remaining := status.Timeout - elapsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		line("  %-22s %s", "elapsed", elapsed.Truncate(time.Second))
+		line("  %-22s %s", "remaining", remaining.Truncate(time.Second))
+	}
+
+	head("throughput")
+	var averageTPS, averageOPS float64
+	if !status.Started.IsZero() {
+		if seconds := now.Sub(status.Started).Seconds(); seconds > 0 {
+			averageTPS = float64(requests-countError) / seconds
+			averageOPS = float64(operations) / seconds
+		}
+	}
+	line("  %-22s %10.2f  (average %10.2f)", "TPS", float64(windowConfirmed)/dashboardWindow.Seconds(), averageTPS)
+	line("  %-22s %10.2f  (average %10.2f)", "OPS", float64(windowOperations)/dashboardWindow.Seconds(), averageOPS)
+	line("  %-22s %10d", "# requests", requests)
+	line("  %-22s %10d", "# operations", operations)
+
+	head(fmt.Sprintf("latency (last %s)", dashboardWindow))
+	if len(latencies) < 1 {
+		line("  %-22s", "no records")
+	} else {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		for _, p := range []float64{50, 90, 99} {
+			line("  %-22s %s", fmt.Sprintf("p%v", p), dashboardPercentile(latencies, p))
+		}
+		line("  %-22s %s", "max", latencies[len(latencies)-1])
+	}
+
+	head("concurrency")
+	line("  %-22s %d / %d", "in-flight", status.Running, status.T)
+	line("  %-22s %d", "operations", status.Operations)
+	line("  %-22s %d", "block height", status.BlockHeight)
+
+	head("error")
+	if len(errorTypes) < 1 {
+		line("  %-22s", "no error")
+	} else {
+		var keys []string
+		for k := range errorTypes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			line("  %-40s %10d", k, errorTypes[k])
+		}
+	}
+
+	head("endpoint")
+	for _, e := range status.Endpoints {
+		line(
+			"  %-40s requests=%-8d errors=%-6d problems=%-8d last=%s",
+			e.Endpoint,
+			e.Requests,
+			e.Errors,
+			e.Problems,
+			e.LastElapsed.Truncate(time.Millisecond),
+		)
+		if len(e.LastError) > 0 {
+			line("  %-40s last error: %s", "", e.LastError)
+		}
+	}
+
+	fmt.Fprint(d.output, "\x1b[H\x1b[2J"+strings.Join(lines, "\x1b[K\n")+"\x1b[K\n")
+}
+
+func dashboardPercentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i].Truncate(time.Millisecond)
+}
+
+// attachDashboard shows the dashboard of hotter until it is detached; after
+// hotter finished, the result summary is printed by printResultFile.
+func attachDashboard(hotter *hotbody.Hotter) (detach func(), err error) {
+	d := newDashboard(hotter, os.Stdout)
+
+	done := make(chan bool)
+	rendered := make(chan bool)
+	go func() {
+		d.run(done)
+		close(rendered)
+	}()
+
+	detach = func() {
+		close(done)
+		<-rendered
+	}
+
+	return
+}
+
+// printResultFile prints the result summary of <result log> like `result`
+// command.
+func printResultFile(resultFile string) (err error) {
+	var f *os.File
+	if f, err = os.Open(resultFile); err != nil {
+		return
+	}
+	defer f.Close()
+
+	_, err = printResult(f, os.Stdout)
+
+	return
+}
@@ -1,21 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"boscoin.io/sebak/lib/common"
-	"boscoin.io/sebak/lib/node"
 	"github.com/spf13/cobra"
 	"github.com/stellar/go/keypair"
 
 	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+	"github.com/spikeekips/sebak-hot-body/hotbody/runner"
 )
 
 var (
@@ -55,6 +55,11 @@ func init() {
 	goCmd.Flags().StringVar(&flagTimeout, "timeout", flagTimeout, "timeout for running")
 	goCmd.Flags().IntVar(&flagOperations, "operations", flagOperations, "number of operations in one transaction")
 	goCmd.Flags().StringVar(&flagResultOutput, "result-output", flagResultOutput, "result output file")
+	goCmd.Flags().BoolVar(&flagDashboard, "dashboard", flagDashboard, "show dashboard instead of log")
+	goCmd.Flags().StringVar(&flagControl, "control", flagControl, "address of control API, tcp address or 'unix://<socket file>'")
+	goCmd.Flags().StringArrayVar(&flagAsserts, "assert", flagAsserts, "assertion of metric, '<metric><operator><value>', 'error_rate<0.1%', 'p99<8s'")
+	goCmd.Flags().StringVar(&flagAssertFile, "assert-file", flagAssertFile, "file of assertions, one assertion in one line")
+	goCmd.Flags().Int64Var(&flagSeed, "seed", flagSeed, "seed for testing accounts and random choices; if 0, new seed is given")
 
 	rootCmd.AddCommand(goCmd)
 }
@@ -65,22 +70,8 @@ func parseGoFlags(args []string) {
 	if len(args) < 1 {
 		printError(goCmd, fmt.Errorf("<secret seed> is missing"))
 	}
-	if parsedKP, err := keypair.Parse(args[0]); err != nil {
-		printError(goCmd, fmt.Errorf("invalid <secret seed>: %v", err))
-	} else {
-		var ok bool
-		if kp, ok = parsedKP.(*keypair.Full); !ok {
-			printError(goCmd, fmt.Errorf("invalid <secret seed>: not secret seed"))
-		}
-	}
-
-	for _, i := range strings.Split(flagSEBAKEndpoint, ",") {
-		if p, err := common.ParseEndpoint(i); err != nil {
-			printFlagsError(goCmd, "--sebak", err)
-		} else {
-			sebakEndpoints = append(sebakEndpoints, p)
-		}
-	}
+	kp = parseSecretSeed(goCmd, args[0])
+	parseSEBAKEndpoints(goCmd)
 
 	if flagConcurrentTransaction < 1 {
 		printFlagsError(goCmd, "--concurrent", errors.New("at least bigger than 0"))
@@ -106,6 +97,8 @@ func parseGoFlags(args []string) {
 
 	setLogging()
 
+	parseAssertFlags(goCmd)
+
 	parsedFlags := []interface{}{}
 	parsedFlags = append(parsedFlags, "\n\tsebak", flagSEBAKEndpoint)
 	parsedFlags = append(parsedFlags, "\n\tlog-level", flagLogLevel)
@@ -117,79 +110,121 @@ func parseGoFlags(args []string) {
 	parsedFlags = append(parsedFlags, "\n\tconfirm-duration", flagConfirmDuration)
 	parsedFlags = append(parsedFlags, "\n\tresult-output", flagResultOutput)
 	parsedFlags = append(parsedFlags, "\n\toperations", flagOperations)
+	parsedFlags = append(parsedFlags, "\n\tdashboard", flagDashboard)
+	parsedFlags = append(parsedFlags, "\n\tcontrol", flagControl)
+	parsedFlags = append(parsedFlags, "\n\tassert", flagAsserts)
+	parsedFlags = append(parsedFlags, "\n\tassert-file", flagAssertFile)
+	parsedFlags = append(parsedFlags, "\n\tseed", flagSeed)
 	parsedFlags = append(parsedFlags, "\n", "")
 
 	log.Debug("parsed flags:", parsedFlags...)
 }
 
-func runGo() {
-	var err error
+// parseSecretSeed parses the <secret seed> of init account.
+func parseSecretSeed(c *cobra.Command, seed string) *keypair.Full {
+	parsedKP, err := keypair.Parse(seed)
+	if err != nil {
+		printError(c, fmt.Errorf("invalid <secret seed>: %v", err))
+	}
 
-	// request node info to sebak
-	headers := http.Header{}
-	headers.Set("Content-Type", "application/json")
-	headers.Set("User-Agent", "sebak-hot-body/v1.0")
-
-	var clients []*hotbody.HTTP2Client
-	for _, i := range sebakEndpoints {
-		var client *hotbody.HTTP2Client
-		client, err = hotbody.NewHTTP2Client(
-			requestTimeout,
-			(*url.URL)(i),
-			headers,
-		)
-		if err != nil {
-			printError(goCmd, fmt.Errorf("failed to create HTTP2Client: %v", err))
-		}
-		client.Transport().MaxIdleConnsPerHost = flagConcurrentTransaction + 100
-		clients = append(clients, client)
+	full, ok := parsedKP.(*keypair.Full)
+	if !ok {
+		printError(c, fmt.Errorf("invalid <secret seed>: not secret seed"))
 	}
 
-	var nodeInfo node.NodeInfo
-	for _, client := range clients {
-		var b []byte
-		if b, err = client.Get("/", nil); err != nil {
-			printFlagsError(goCmd, "--sebak", err)
-		}
+	return full
+}
 
-		if nodeInfo, err = node.NewNodeInfoFromJSON(b); err != nil {
-			printError(goCmd, fmt.Errorf("failed to parse node info response: %v", err))
+func parseSEBAKEndpoints(c *cobra.Command) {
+	for _, i := range strings.Split(flagSEBAKEndpoint, ",") {
+		if p, err := common.ParseEndpoint(i); err != nil {
+			printFlagsError(c, "--sebak", err)
+		} else {
+			sebakEndpoints = append(sebakEndpoints, p)
 		}
-		log.Debug("sebak info", "sebak", client.URL())
-		log.Debug(fmt.Sprintf(
-			`================================================================================
-%s
-================================================================================
-`, b))
 	}
+}
 
-	hotterConfig := hotbody.HotterConfig{
-		Node:            nodeInfo,
-		T:               flagConcurrentTransaction,
+func runGo() {
+	var err error
+
+	var endpoints []string
+	for _, endpoint := range sebakEndpoints {
+		endpoints = append(endpoints, endpoint.String())
+	}
+
+	attach := []runner.AttachFunc{
+		func(hotter *hotbody.Hotter) (func(), error) {
+			log.Info("seed", "seed", hotter.Seed)
+			return func() {}, nil
+		},
+	}
+	if len(flagControl) > 0 {
+		attach = append(attach, attachControl)
+	}
+	if flagDashboard {
+		attach = append(attach, attachDashboard)
+	}
+
+	_, err = runner.Run(context.Background(), runner.Config{
+		Endpoints:       endpoints,
 		KP:              kp,
-		InitAccount:     kp.Address(),
+		T:               flagConcurrentTransaction,
+		Operations:      flagOperations,
 		Timeout:         timeout,
 		RequestTimeout:  requestTimeout,
 		ConfirmDuration: confirmDuration,
 		ResultOutput:    flagResultOutput,
-		Operations:      flagOperations,
+		Seed:            flagSeed,
+		Attach:          attach,
+	})
+
+	switch err.(type) {
+	case nil:
+	case *runner.ErrorNodeUnreachable:
+		printFlagsError(goCmd, "--sebak", err)
+	default:
+		// NOTE without payments, the result is still printed
+		if err != analysis.ErrNoRecords {
+			fmt.Fprintf(os.Stderr, "end with error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	var hotter *hotbody.Hotter
-	hotter, err = hotbody.NewHotter(hotterConfig, clients)
-	if err != nil {
-		printError(goCmd, fmt.Errorf("something wrong: %v", err))
+	if flagDashboard {
+		if err = printResultFile(flagResultOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print result: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	if _, err := hotter.GetAccount(kp.Address(), true); err != nil {
-		printError(goCmd, fmt.Errorf("account of <secret seed> not found"))
-	}
+	if len(assertions) > 0 {
+		var rl analysis.Log
+		if rl, err = loadResultFile(flagResultOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load result: %v\n", err)
+			os.Exit(1)
+		}
 
-	if err := hotter.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "end with error: %v\n", err)
-		os.Exit(1)
+		checkAssertions(newResultReport(rl))
 	}
 
 	log.Debug("hot-body ended")
 	os.Exit(0)
 }
+
+// attachControl starts the control API of hotter.
+func attachControl(hotter *hotbody.Hotter) (detach func(), err error) {
+	var controlServer *hotbody.ControlServer
+	if controlServer, err = hotbody.NewControlServer(hotter, flagControl); err != nil {
+		printFlagsError(goCmd, "--control", err)
+	}
+	controlServer.Start()
+
+	log.Info("control API started", "address", flagControl)
+
+	detach = func() {
+		controlServer.Close()
+	}
+
+	return
+}
@@ -20,6 +20,12 @@ const (
 	defaultConfirmDuration       string      = "60s"
 	defaultTimeout               string      = "1m"
 	defaultOperations            int         = 1
+	defaultBucketWidth           string      = "auto"
+	defaultFormat                string      = "terminal"
+	defaultChartsFormat          string      = "svg"
+	defaultFollowInterval        string      = "5s"
+	defaultFollowWindow          string      = "1m"
+	defaultReplaySpeed           float64     = 1
 )
 
 var (
@@ -34,6 +40,34 @@ var (
 	flagResultOutput          string
 	flagOperations            int = defaultOperations
 	flagBrief                 bool
+	flagDashboard             bool
+	flagControl               string
+	flagBucketWidth           string = defaultBucketWidth
+	flagWindow                string
+	flagTimeSeriesOutput      string
+	flagFormat                string = defaultFormat
+	flagThresholds            []string
+	flagAsserts               []string
+	flagAssertFile            string
+	flagJUnit                 string
+	flagErrorCatalogue        string
+	flagCharts                string
+	flagChartsFormat          string = defaultChartsFormat
+	flagFollow                bool
+	flagSince                 string
+	flagUntil                 string
+	flagSkipWarmup            string
+	flagSkipCooldown          string
+	flagPhase                 string
+	flagMigrateOutput         string
+	flagFollowInterval        string = defaultFollowInterval
+	flagFollowWindow          string = defaultFollowWindow
+	flagConfig                string
+	flagProfile               string
+	flagPrintConfig           bool
+	flagReplaySpeed           float64 = defaultReplaySpeed
+	flagReplayTimeout         string
+	flagSeed                  int64
 )
 
 var (
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// maxRecordLineSize is the maximum size of the line of <result log>; the
+// `create-accounts` record can have thousands of addresses.
+const maxRecordLineSize int = 64 * 1024 * 1024
+
+var (
+	migrateCmd  *cobra.Command
+	migrateFile string
+)
+
+func init() {
+	migrateCmd = &cobra.Command{
+		Use:   "migrate <result log>",
+		Short: "Upgrade old result log to the current schema version",
+		Run: func(c *cobra.Command, args []string) {
+			parseMigrateFlags(args)
+
+			runMigrate()
+		},
+	}
+
+	migrateCmd.Flags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "log level, {crit, error, warn, info, debug}")
+	migrateCmd.Flags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
+	migrateCmd.Flags().StringVar(&flagLog, "log", flagLog, "set log file")
+	migrateCmd.Flags().StringVar(&flagMigrateOutput, "output", flagMigrateOutput, "output file; if empty, stdout")
+
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func parseMigrateFlags(args []string) {
+	setLogging()
+
+	if len(args) < 1 {
+		printError(migrateCmd, fmt.Errorf("<result log> is missing"))
+	}
+	migrateFile = args[0]
+
+	if len(flagMigrateOutput) > 0 && flagMigrateOutput == migrateFile {
+		printFlagsError(migrateCmd, "--output", fmt.Errorf("same with <result log>"))
+	}
+
+	parsedFlags := []interface{}{}
+	parsedFlags = append(parsedFlags, "\n\tresult-log", migrateFile)
+	parsedFlags = append(parsedFlags, "\n\toutput", flagMigrateOutput)
+	parsedFlags = append(parsedFlags, "\n\tlog-level", flagLogLevel)
+	parsedFlags = append(parsedFlags, "\n\tlog-format", flagLogFormat)
+	parsedFlags = append(parsedFlags, "\n\tlog", flagLog)
+	parsedFlags = append(parsedFlags, "\n", "")
+
+	log.Debug("parsed flags:", parsedFlags...)
+}
+
+// migrateResultLog upgrades the records of <result log>; the v0 records get
+// the new run ID and the sequence numbers by their order.
+func migrateResultLog(r io.Reader, w io.Writer) (records, upgraded int, err error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxRecordLineSize)
+
+	run := hotbody.NewRunID()
+	for sc.Scan() {
+		b := sc.Bytes()
+		if len(b) < 1 {
+			continue
+		}
+
+		var migrated []byte
+		var ok bool
+		if migrated, ok, err = hotbody.MigrateRecord(b, run, uint64(records)); err != nil {
+			err = fmt.Errorf("failed to migrate record at line %d; %v", records+1, err)
+			return
+		}
+		if ok {
+			upgraded++
+		}
+
+		if _, err = fmt.Fprintln(w, string(migrated)); err != nil {
+			return
+		}
+		records++
+	}
+
+	err = sc.Err()
+
+	return
+}
+
+func runMigrate() {
+	r, err := os.Open(migrateFile)
+	if err != nil {
+		printError(migrateCmd, fmt.Errorf("failed to open <result log>; %v", err))
+	}
+	defer r.Close()
+
+	var w io.Writer = os.Stdout
+	if len(flagMigrateOutput) > 0 {
+		var f *os.File
+		if f, err = os.Create(flagMigrateOutput); err != nil {
+			printError(migrateCmd, fmt.Errorf("failed to create output; %v", err))
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	records, upgraded, err := migrateResultLog(r, bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		printError(migrateCmd, err)
+	}
+
+	log.Info(
+		"migrated",
+		"file", migrateFile,
+		"version", hotbody.RecordVersion,
+		"records", records,
+		"upgraded", upgraded,
+	)
+}
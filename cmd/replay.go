@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+	"github.com/spikeekips/sebak-hot-body/hotbody/runner"
+)
+
+var (
+	replayCmd      *cobra.Command
+	replayFile     string
+	replayPayments []hotbody.ReplayPayment
+	replayLog      analysis.Log
+)
+
+func init() {
+	replayCmd = &cobra.Command{
+		Use:   "replay <secret seed> <result log>",
+		Short: "Replay the payments of result log with new accounts",
+		Run: func(c *cobra.Command, args []string) {
+			parseReplayFlags(args)
+
+			runReplay()
+		},
+	}
+
+	replayCmd.Flags().StringVar(&flagSEBAKEndpoint, "sebak", flagSEBAKEndpoint, "sebak endpoint")
+	replayCmd.Flags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "log level, {crit, error, warn, info, debug}")
+	replayCmd.Flags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
+	replayCmd.Flags().StringVar(&flagLog, "log", flagLog, "set log file")
+	replayCmd.Flags().StringVar(&flagRequestTimeout, "request-timeout", flagRequestTimeout, "timeout for requests")
+	replayCmd.Flags().StringVar(&flagConfirmDuration, "confirm-duration", flagConfirmDuration, "duration for checking transaction confirmed")
+	replayCmd.Flags().StringVar(&flagResultOutput, "result-output", flagResultOutput, "result output file")
+	replayCmd.Flags().Float64Var(&flagReplaySpeed, "speed", flagReplaySpeed, "time scale of replaying, '2' is 2x faster and '0.5' is 2x slower")
+	replayCmd.Flags().StringVar(&flagReplayTimeout, "timeout", flagReplayTimeout, "timeout for replaying; if empty, until the last payment is sent")
+	replayCmd.Flags().Int64Var(&flagSeed, "seed", flagSeed, "seed for testing accounts; if 0, new seed is given")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+func parseReplayFlags(args []string) {
+	var err error
+
+	if len(args) < 2 {
+		printError(replayCmd, fmt.Errorf("<secret seed> and <result log> are missing"))
+	}
+	kp = parseSecretSeed(replayCmd, args[0])
+	replayFile = args[1]
+	parseSEBAKEndpoints(replayCmd)
+
+	if flagReplaySpeed <= 0 {
+		printFlagsError(replayCmd, "--speed", errors.New("at least bigger than 0"))
+	}
+	if len(flagRequestTimeout) < 1 {
+		printFlagsError(replayCmd, "--request-timeout", errors.New("must be given"))
+	} else if requestTimeout, err = time.ParseDuration(flagRequestTimeout); err != nil {
+		printFlagsError(replayCmd, "--request-timeout", err)
+	}
+	if len(flagConfirmDuration) < 1 {
+		printFlagsError(replayCmd, "--confirm-duration", errors.New("must be given"))
+	} else if confirmDuration, err = time.ParseDuration(flagConfirmDuration); err != nil {
+		printFlagsError(replayCmd, "--confirm-duration", err)
+	}
+	if len(flagReplayTimeout) > 0 {
+		if timeout, err = time.ParseDuration(flagReplayTimeout); err != nil {
+			printFlagsError(replayCmd, "--timeout", err)
+		} else if timeout <= 0 {
+			printFlagsError(replayCmd, "--timeout", errors.New("at least bigger than 0"))
+		}
+	}
+	if flagResultOutput == replayFile {
+		printFlagsError(replayCmd, "--result-output", errors.New("same with <result log>"))
+	}
+
+	setLogging()
+
+	var f *os.File
+	if f, err = os.Open(replayFile); err != nil {
+		printError(replayCmd, fmt.Errorf("failed to open <result log>; %v", err))
+	}
+	defer f.Close()
+
+	if replayLog, err = analysis.Load(f); err != nil {
+		printError(replayCmd, fmt.Errorf("failed to load <result log>; %v", err))
+	}
+
+	records := append(append([]hotbody.Record{}, replayLog.Records...), replayLog.SEBAKErrorRecords...)
+	if replayPayments, err = hotbody.NewReplayPayments(records); err != nil {
+		printError(replayCmd, err)
+	} else if len(replayPayments) < 1 {
+		printError(replayCmd, errors.New("no payments to replay"))
+	}
+
+	parsedFlags := []interface{}{}
+	parsedFlags = append(parsedFlags, "\n\tresult-log", replayFile)
+	parsedFlags = append(parsedFlags, "\n\tsebak", flagSEBAKEndpoint)
+	parsedFlags = append(parsedFlags, "\n\tspeed", flagReplaySpeed)
+	parsedFlags = append(parsedFlags, "\n\tseed", flagSeed)
+	parsedFlags = append(parsedFlags, "\n\ttimeout", flagReplayTimeout)
+	parsedFlags = append(parsedFlags, "\n\trequest-timeout", flagRequestTimeout)
+	parsedFlags = append(parsedFlags, "\n\tconfirm-duration", flagConfirmDuration)
+	parsedFlags = append(parsedFlags, "\n\tresult-output", flagResultOutput)
+	parsedFlags = append(parsedFlags, "\n\tlog-level", flagLogLevel)
+	parsedFlags = append(parsedFlags, "\n\tlog-format", flagLogFormat)
+	parsedFlags = append(parsedFlags, "\n\tlog", flagLog)
+	parsedFlags = append(parsedFlags, "\n", "")
+
+	log.Debug("parsed flags:", parsedFlags...)
+}
+
+func runReplay() {
+	var err error
+
+	sources := map[string]bool{}
+	for _, p := range replayPayments {
+		sources[p.Source] = true
+	}
+	duration := time.Duration(float64(replayPayments[len(replayPayments)-1].Offset) / flagReplaySpeed)
+	// NOTE with `--timeout`, the payments after it are not sent
+	if timeout > 0 && timeout < duration {
+		duration = timeout
+	}
+
+	log.Info(
+		"replay",
+		"result-log", replayFile,
+		"payments", len(replayPayments),
+		"accounts", len(hotbody.ReplayAccounts(replayPayments)),
+		"sources", len(sources),
+		"duration", duration,
+	)
+
+	var clients []*hotbody.HTTP2Client
+	if clients, err = runner.NewClients(sebakEndpoints, requestTimeout, len(sources)); err != nil {
+		printError(replayCmd, err)
+	}
+
+	if nodeInfo, err = runner.GetNodeInfo(context.Background(), clients); err != nil {
+		switch err.(type) {
+		case *runner.ErrorNodeUnreachable:
+			printFlagsError(replayCmd, "--sebak", err)
+		default:
+			printError(replayCmd, err)
+		}
+	}
+
+	if nodeInfo.Policy.NetworkID != replayLog.Config.Node.Policy.NetworkID {
+		log.Warn(
+			"network id is different",
+			"replayed", replayLog.Config.Node.Policy.NetworkID,
+			"sebak", nodeInfo.Policy.NetworkID,
+		)
+	}
+
+	// NOTE the concurrency is the number of source accounts
+	hotterConfig := hotbody.HotterConfig{
+		Node:            nodeInfo,
+		T:               len(sources),
+		KP:              kp,
+		InitAccount:     kp.Address(),
+		Timeout:         duration,
+		RequestTimeout:  requestTimeout,
+		ConfirmDuration: confirmDuration,
+		ResultOutput:    flagResultOutput,
+		Operations:      replayLog.Config.Operations,
+		Seed:            flagSeed,
+		Replay: &hotbody.ReplayConfig{
+			Source: replayFile,
+			Run:    replayLog.Run,
+			Speed:  flagReplaySpeed,
+		},
+	}
+
+	var hotter *hotbody.Hotter
+	if hotter, err = hotbody.NewHotter(hotterConfig, clients); err != nil {
+		printError(replayCmd, fmt.Errorf("something wrong: %v", err))
+	}
+	log.Info("seed", "seed", hotter.Seed)
+
+	if _, err := hotter.GetAccount(kp.Address(), true); err != nil {
+		printError(replayCmd, fmt.Errorf("account of <secret seed> not found"))
+	}
+
+	if err = hotter.Replay(replayPayments, flagReplaySpeed); err != nil {
+		fmt.Fprintf(os.Stderr, "end with error: %v\n", err)
+		os.Exit(1)
+	}
+
+	log.Info("replay ended", "result-output", flagResultOutput)
+	os.Exit(0)
+}
@@ -1,32 +1,31 @@
 package cmd
 
 import (
-	"bufio"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
+	"io"
 	"os"
-	"sort"
+	"path/filepath"
 	"time"
 
-	"github.com/apcera/termtables"
 	"github.com/spf13/cobra"
 
-	"github.com/spikeekips/sebak-hot-body/hotbody"
-
-	"boscoin.io/sebak/lib/common"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
 )
 
 var (
-	resultCmd    *cobra.Command
-	resultOutput *os.File
-	started      time.Time
-	ended        time.Time
+	resultCmd     *cobra.Command
+	resultFiles   []string
+	bucketWidth   time.Duration
+	windows       []time.Duration
+	chartsFormats []string
 )
 
+var percentiles = []float64{50, 75, 90, 95, 99, 99.9}
+
 func init() {
 	resultCmd = &cobra.Command{
-		Use:   "result <result log>",
+		Use:   "result <result log>...",
 		Short: "Parse result",
 		Run: func(c *cobra.Command, args []string) {
 			parseResultFlags(args)
@@ -39,6 +38,20 @@ func init() {
 	resultCmd.Flags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
 	resultCmd.Flags().StringVar(&flagLog, "log", flagLog, "set log file")
 	resultCmd.Flags().BoolVar(&flagBrief, "brief", flagBrief, "show only result")
+	resultCmd.Flags().BoolVar(&flagFollow, "follow", flagFollow, "follow <result log>, which is still being written, until it is ended")
+	resultCmd.Flags().StringVar(&flagFollowInterval, "follow-interval", flagFollowInterval, "interval to render with --follow")
+	resultCmd.Flags().StringVar(&flagFollowWindow, "follow-window", flagFollowWindow, "recent window of statistics with --follow")
+	resultCmd.Flags().StringVar(&flagBucketWidth, "bucket-width", flagBucketWidth, "bucket width of elapsed time distribution, duration or 'auto'")
+	resultCmd.Flags().StringVar(&flagWindow, "window", flagWindow, "windows of time series, comma separated durations, '1s,10s,1m'")
+	resultCmd.Flags().StringVar(&flagTimeSeriesOutput, "timeseries-output", flagTimeSeriesOutput, "export time series to file, '.csv' or '.json'")
+	resultCmd.Flags().StringVar(&flagFormat, "format", flagFormat, "output format, {terminal, json, csv, markdown, html}")
+	resultCmd.Flags().StringArrayVar(&flagAsserts, "assert", flagAsserts, "assertion of metric, '<metric><operator><value>', 'error_rate<0.1%', 'p99<8s'")
+	resultCmd.Flags().StringVar(&flagAssertFile, "assert-file", flagAssertFile, "file of assertions, one assertion in one line")
+	resultCmd.Flags().StringVar(&flagJUnit, "junit", flagJUnit, "export JUnit XML report to file")
+	resultCmd.Flags().StringVar(&flagCharts, "charts", flagCharts, "write charts to directory")
+	resultCmd.Flags().StringVar(&flagChartsFormat, "charts-format", flagChartsFormat, "format of charts, comma separated, {svg, png}")
+	addResultFilterFlags(resultCmd)
+	resultCmd.Flags().StringVar(&flagErrorCatalogue, "error-catalogue", flagErrorCatalogue, "JSON file of SEBAK errors, which are added to the error catalogue")
 
 	rootCmd.AddCommand(resultCmd)
 }
@@ -51,357 +64,160 @@ func parseResultFlags(args []string) {
 	if len(args) < 1 {
 		printError(resultCmd, fmt.Errorf("<result log> is missing"))
 	}
-	flagResultOutput = args[0]
-
-	if resultOutput, err = os.Open(flagResultOutput); err != nil {
-		printError(resultCmd, fmt.Errorf("failed to open <result log>; %v", err))
-	}
-
-	parsedFlags := []interface{}{}
-	parsedFlags = append(parsedFlags, "\n\tresult-log", flagResultOutput)
-	parsedFlags = append(parsedFlags, "\n\tlog-level", flagLogLevel)
-	parsedFlags = append(parsedFlags, "\n\tlog-format", flagLogFormat)
-	parsedFlags = append(parsedFlags, "\n\tlog", flagLog)
-	parsedFlags = append(parsedFlags, "\n", "")
-
-	log.Debug("parsed flags:", parsedFlags...)
-}
-
-func loadLine(l string) (record hotbody.Record, err error) {
-	var d map[string]interface{}
-	if err = json.Unmarshal([]byte(l), &d); err != nil {
-		return
-	}
-
-	if _, found := d["type"]; !found {
-		err = fmt.Errorf("found invalid format")
-		return
+	if resultFiles, err = expandResultFiles(args); err != nil {
+		printError(resultCmd, err)
 	}
 
-	recordType := d["type"].(string)
-	switch recordType {
-	case "started":
-		started, _ = common.ParseISO8601(d["time"].(string))
-		return
-	case "ended":
-		ended, _ = common.ParseISO8601(d["time"].(string))
-		return
-	case "config":
-		var b []byte
-		if b, err = json.Marshal(d["config"]); err != nil {
-			return
+	if flagFollow {
+		if len(resultFiles) != 1 {
+			printFlagsError(resultCmd, "--follow", errors.New("only one <result log> can be followed"))
 		}
-		var hotterConfig hotbody.HotterConfig
-		if err = json.Unmarshal(b, &hotterConfig); err != nil {
-			return
+		if followInterval, err = time.ParseDuration(flagFollowInterval); err != nil {
+			printFlagsError(resultCmd, "--follow-interval", err)
+		} else if followInterval <= 0 {
+			printFlagsError(resultCmd, "--follow-interval", errors.New("at least bigger than 0"))
 		}
-
-		record = hotterConfig
-	case "create-accounts":
-		var createAccounts hotbody.RecordCreateAccounts
-		if err = json.Unmarshal([]byte(l), &createAccounts); err != nil {
-			return
-		}
-
-		record = createAccounts
-	case "payment":
-		var payment hotbody.RecordPayment
-		if err = json.Unmarshal([]byte(l), &payment); err != nil {
-			return
+		if followWindow, err = time.ParseDuration(flagFollowWindow); err != nil {
+			printFlagsError(resultCmd, "--follow-window", err)
+		} else if followWindow <= 0 {
+			printFlagsError(resultCmd, "--follow-window", errors.New("at least bigger than 0"))
 		}
+	}
 
-		record = payment
-	case "sebak-error":
-		var sebakError hotbody.RecordSEBAKError
-		if err = json.Unmarshal([]byte(l), &sebakError); err != nil {
-			return
+	if flagBucketWidth != "auto" {
+		if bucketWidth, err = time.ParseDuration(flagBucketWidth); err != nil {
+			printFlagsError(resultCmd, "--bucket-width", err)
+		} else if bucketWidth <= 0 {
+			printFlagsError(resultCmd, "--bucket-width", errors.New("at least bigger than 0"))
 		}
+	}
 
-		record = sebakError
+	switch flagFormat {
+	case "terminal", "json", "csv", "markdown", "html":
 	default:
-		err = fmt.Errorf("unknown type found: %v", recordType)
-		return
+		printFlagsError(resultCmd, "--format", fmt.Errorf("'%s'", flagFormat))
 	}
 
-	return
-}
-
-func runResult() {
-	defer resultOutput.Close()
-
-	var err error
-
-	sc := bufio.NewScanner(resultOutput)
-	sc.Split(bufio.ScanLines)
-
-	var config hotbody.HotterConfig
-
-	sc.Scan()
-	headLine := sc.Text()
-
-	var record hotbody.Record
-	if record, err = loadLine(headLine); err != nil {
-		printError(resultCmd, fmt.Errorf("something wrong to read <result log>; %v; %v", err, headLine))
-	} else {
-		config = record.(hotbody.HotterConfig)
+	if windows, err = parseWindows(flagWindow); err != nil {
+		printFlagsError(resultCmd, "--window", err)
 	}
-	log.Debug("config loaded", "config", config)
-
-	log.Debug("trying to load record")
-	var records []hotbody.Record
-	sebakErrors := map[int]int{}
-	for sc.Scan() {
-		s := sc.Text()
-
-		if record, err = loadLine(s); err != nil {
-			printError(resultCmd, fmt.Errorf("something wrong to read <result log>; %v; %v", err, s))
-		} else if record == nil {
-			continue
+	if len(flagTimeSeriesOutput) > 0 {
+		if len(windows) < 1 {
+			printFlagsError(resultCmd, "--timeseries-output", errors.New("--window must be given"))
 		}
-		if record.GetType() != "payment" {
-			if sr, ok := record.(hotbody.RecordSEBAKError); ok {
-				e := sr.GetRawError()
-				if _, ok := e["data"]; !ok {
-					continue
-				}
-
-				j := e["data"].(map[string]interface{})["body"].(string)
-
-				var body map[string]interface{}
-				json.Unmarshal([]byte(j), &body)
-				if _, ok := body["code"]; !ok {
-					continue
-				}
-				sebakErrors[int(body["code"].(float64))]++
-			}
-
-			continue
+		switch filepath.Ext(flagTimeSeriesOutput) {
+		case ".csv", ".json":
+		default:
+			printFlagsError(resultCmd, "--timeseries-output", errors.New("only '.csv' and '.json' are supported"))
 		}
-
-		records = append(records, record)
-	}
-	log.Debug("records loaded", "count", len(records))
-
-	if len(records) < 1 {
-		fmt.Println("no records found")
-		os.Exit(1)
 	}
 
-	if err = sc.Err(); err != nil {
-		printError(resultCmd, fmt.Errorf("something wrong to read <result log>; %v", err))
+	if chartsFormats, err = parseChartsFormat(flagChartsFormat); err != nil {
+		printFlagsError(resultCmd, "--charts-format", err)
 	}
 
-	var maxElapsedTime float64
-	var minElapsedTime float64 = -1
-	var step float64 = 50000000000
-
-	els := map[float64]int{}
-	var countError int
-	errorTypes := map[hotbody.RecordErrorType]int{}
-	for _, r := range records {
-		es := float64(r.GetElapsed())
+	parseResultFilterFlags(resultCmd)
+	parseAssertFlags(resultCmd)
+	parseErrorCatalogueFlag(resultCmd)
 
-		i := int(es/step) * int(step)
-		els[float64(i)]++
+	parsedFlags := []interface{}{}
+	parsedFlags = append(parsedFlags, "\n\tresult-log", resultFiles)
+	parsedFlags = append(parsedFlags, "\n\tfollow", flagFollow)
+	parsedFlags = append(parsedFlags, "\n\tfollow-interval", flagFollowInterval)
+	parsedFlags = append(parsedFlags, "\n\tfollow-window", flagFollowWindow)
+	parsedFlags = append(parsedFlags, "\n\tsince", flagSince)
+	parsedFlags = append(parsedFlags, "\n\tuntil", flagUntil)
+	parsedFlags = append(parsedFlags, "\n\tskip-warmup", flagSkipWarmup)
+	parsedFlags = append(parsedFlags, "\n\tskip-cooldown", flagSkipCooldown)
+	parsedFlags = append(parsedFlags, "\n\tphase", flagPhase)
+	parsedFlags = append(parsedFlags, "\n\tbucket-width", flagBucketWidth)
+	parsedFlags = append(parsedFlags, "\n\twindow", flagWindow)
+	parsedFlags = append(parsedFlags, "\n\ttimeseries-output", flagTimeSeriesOutput)
+	parsedFlags = append(parsedFlags, "\n\tformat", flagFormat)
+	parsedFlags = append(parsedFlags, "\n\tassert", flagAsserts)
+	parsedFlags = append(parsedFlags, "\n\tassert-file", flagAssertFile)
+	parsedFlags = append(parsedFlags, "\n\tjunit", flagJUnit)
+	parsedFlags = append(parsedFlags, "\n\terror-catalogue", flagErrorCatalogue)
+	parsedFlags = append(parsedFlags, "\n\tcharts", flagCharts)
+	parsedFlags = append(parsedFlags, "\n\tcharts-format", flagChartsFormat)
+	parsedFlags = append(parsedFlags, "\n\tlog-level", flagLogLevel)
+	parsedFlags = append(parsedFlags, "\n\tlog-format", flagLogFormat)
+	parsedFlags = append(parsedFlags, "\n\tlog", flagLog)
+	parsedFlags = append(parsedFlags, "\n", "")
 
-		maxElapsedTime = math.Max(maxElapsedTime, es)
-		if minElapsedTime < 0 {
-			minElapsedTime = es
-		} else {
-			minElapsedTime = math.Min(minElapsedTime, es)
-		}
+	log.Debug("parsed flags:", parsedFlags...)
+}
 
-		if r.GetError() == nil {
-			continue
-		}
-		countError++
-		errorTypes[r.GetErrorType()]++
-	}
+func runResult() {
+	var report *resultReport
 
-	var elsKeys sort.IntSlice
-	for i := float64(0); i < ((maxElapsedTime/step)*step)+step; i += step {
-		if _, ok := els[i]; !ok {
-			els[i] = 0
+	var err error
+	if flagFollow {
+		report, err = followResult(resultFiles[0], os.Stdout)
+	} else {
+		var rl analysis.Log
+		if rl, err = loadResultFiles(resultFiles); err == nil {
+			report, err = renderResult(rl, os.Stdout)
 		}
-		elsKeys = append(elsKeys, int(i))
 	}
 
-	sort.Sort(elsKeys)
-
-	alignKey := func(s string) string {
-		return fmt.Sprintf("% 20s", s)
+	if err == analysis.ErrNoRecords {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	} else if err != nil {
+		printError(resultCmd, err)
 	}
 
-	alignValue := func(v interface{}) string {
-		var s string
-		switch v.(type) {
-		case float64:
-			s = fmt.Sprintf("%15.10f", v)
-		default:
-			s = fmt.Sprintf("%v", v)
+	if len(flagJUnit) > 0 {
+		if err = writeJUnitFile(flagJUnit, report); err != nil {
+			printError(resultCmd, fmt.Errorf("failed to write JUnit report; %v", err))
 		}
-
-		return fmt.Sprintf("%30s", s)
 	}
 
-	alignHead := func(s string) string {
-		return fmt.Sprintf("* %-10s", s)
-	}
-
-	formatAddress := func(s string) string {
-		return fmt.Sprintf("%s...%s", s[:13], s[len(s)-13:])
+	if len(flagCharts) > 0 {
+		if err = writeCharts(flagCharts, chartsFormats, report); err != nil {
+			printError(resultCmd, fmt.Errorf("failed to write charts; %v", err))
+		}
 	}
 
-	table := termtables.CreateTable()
-
-	if !flagBrief {
-		table.AddRow(alignHead("config"), alignKey("testing time"), alignValue(config.Timeout))
-		table.AddRow("", alignKey("concurrent requests"), alignValue(config.T))
-		table.AddRow("", alignKey("initial account"), alignValue(formatAddress(config.InitAccount)))
-		table.AddRow("", alignKey("request timeout"), alignValue(config.RequestTimeout))
-		table.AddRow("", alignKey("confirm duration"), alignValue(config.ConfirmDuration))
-		table.AddRow("", alignKey("operations"), alignValue(config.Operations))
-		table.AddSeparator()
-	}
+	checkAssertions(report)
 
-	if !flagBrief {
-		table.AddRow(alignHead("network"), alignKey("network id"), alignValue(config.Node.Policy.NetworkID))
-		table.AddRow("", alignKey("initial balance"), alignValue(config.Node.Policy.InitialBalance))
-		table.AddRow("", alignKey("block time"), alignValue(config.Node.Policy.BlockTime))
-		table.AddRow("", alignKey("base reserve"), alignValue(config.Node.Policy.BaseReserve))
-		table.AddRow("", alignKey("base fee"), alignValue(config.Node.Policy.BaseFee))
-		table.AddSeparator()
-	}
+	os.Exit(0)
+}
 
-	if !flagBrief {
-		table.AddRow(alignHead("node"), alignKey("endpoint"), alignValue(config.Node.Node.Endpoint))
-		table.AddRow("", alignKey("address"), alignValue(formatAddress(config.Node.Node.Address)))
-		table.AddRow("", alignKey("state"), alignValue(config.Node.Node.State))
-		table.AddRow("", alignKey("block height"), alignValue(config.Node.Block.Height))
-		table.AddRow("", alignKey("block hash"), alignValue(formatAddress(config.Node.Block.Hash)))
-		table.AddRow("", alignKey("block totaltxs"), alignValue(config.Node.Block.TotalTxs))
-		table.AddRow("", alignKey("block totalops"), alignValue(config.Node.Block.TotalOps))
-		table.AddSeparator()
+// printResult reads the records from <result log> and renders the summary
+// of them into w.
+func printResult(r io.Reader, w io.Writer) (report *resultReport, err error) {
+	var rl analysis.Log
+	if rl, err = analysis.Load(r); err != nil {
+		return
 	}
 
-	lastTime := records[len(records)-1].GetTime()
+	return renderResult(rl, w)
+}
 
-	if !flagBrief {
-		table.AddRow(alignHead("time"), alignKey("started"), alignValue(FormatISO8601(started)))
-		table.AddRow("", alignKey("ended"), alignValue(FormatISO8601(lastTime)))
-		table.AddRow("", alignKey("total elapsed"), alignValue(lastTime.Sub(started)))
-		table.AddSeparator()
+func renderResult(rl analysis.Log, w io.Writer) (report *resultReport, err error) {
+	report = newResultReport(rl)
+	if err = report.Render(w, flagFormat); err != nil {
+		return
 	}
 
-	{
-		table.AddRow(alignHead("result"), alignKey("# requests"), alignValue(len(records)))
-		table.AddRow("", alignKey("# operations"), alignValue(len(records)*config.Operations))
-		table.AddRow(
-			"",
-			alignKey("error rates"),
-			alignValue(
-				fmt.Sprintf(
-					"%2.5f％ (%d/%d)",
-					float64(countError)/float64(len(records))*100,
-					countError,
-					len(records),
-				),
-			),
-		)
-		table.AddRow("", alignKey("max elapsed time"), alignValue(maxElapsedTime/float64(10000000000)))
-		table.AddRow("", alignKey("min elapsed time"), alignValue(minElapsedTime/float64(10000000000)))
-
-		table.AddRow("", alignKey("distribution"), "")
-		for _, e := range elsKeys {
-			span := int(float64(e) / float64(10000000000))
-			c := els[float64(e)]
-
-			table.AddRow(
-				"",
-				"",
-				alignValue(fmt.Sprintf(
-					"%2d-%-2d: %8.5f％ / %5d",
-					span,
-					span+int(step/float64(10000000000)),
-					float64(c)/float64(len(records))*100,
-					c,
-				)),
-			)
-		}
-
-		totalSeconds := lastTime.Sub(started).Seconds()
-
-		ops := float64((len(records))*config.Operations) / float64(totalSeconds)
-		table.AddRow("", alignKey("expected OPS"), alignValue(int(ops)))
-		ops = float64((len(records)-countError)*config.Operations) / float64(totalSeconds)
-		table.AddRow("", alignKey("real OPS"), alignValue(int(ops)))
+	if len(flagTimeSeriesOutput) < 1 {
+		return
 	}
 
-	{
-		table.AddSeparator()
-		if countError < 1 {
-			table.AddRow(alignHead("error"), alignKey("no error"), "")
-		} else {
-			var c int
-			for errorType, errorCount := range errorTypes {
-				h := ""
-				if c == 0 {
-					h = alignHead("error")
-				}
-				c++
-				table.AddRow(
-					h,
-					alignKey(string(errorType)),
-					alignValue(
-						fmt.Sprintf(
-							"%d | % 10s",
-							errorCount,
-							fmt.Sprintf(
-								"%.5f％",
-								float64(errorCount)/float64(countError)*100,
-							),
-						),
-					),
-				)
-			}
-		}
+	var f *os.File
+	if f, err = os.Create(flagTimeSeriesOutput); err != nil {
+		return
 	}
+	defer f.Close()
 
-	{
-		table.AddSeparator()
-		if len(sebakErrors) < 1 {
-			table.AddRow(alignHead("sebak-error"), alignKey("no error"), "")
-		} else {
-			var countSEBAKError int
-			for _, errorCount := range sebakErrors {
-				countSEBAKError += errorCount
-			}
-
-			var c int
-			for errorType, errorCount := range sebakErrors {
-				h := ""
-				if c == 0 {
-					h = alignHead("sebak-error")
-				}
-				c++
-				table.AddRow(
-					h,
-					alignKey(fmt.Sprintf("sebak-error-%d", int(errorType))),
-					alignValue(
-						fmt.Sprintf(
-							"%d | % 10s",
-							errorCount,
-							fmt.Sprintf(
-								"%.5f％",
-								float64(errorCount)/float64(countSEBAKError)*100,
-							),
-						),
-					),
-				)
-			}
-		}
+	switch filepath.Ext(flagTimeSeriesOutput) {
+	case ".csv":
+		err = writeTimeSeriesCSV(f, report.TimeSeries)
+	case ".json":
+		err = writeTimeSeriesJSON(f, report.TimeSeries)
 	}
-	fmt.Fprintf(os.Stdout, table.Render())
 
-	os.Exit(0)
+	return
 }
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+// numberOfAccountRows is the maximum number of accounts in the
+// `account-requests` section; over it, the most and the least used accounts
+// are shown.
+const numberOfAccountRows int = 20
+
+// numberOfListedAccounts is the maximum number of addresses in the row of
+// `account` section, like `never source`.
+const numberOfListedAccounts int = 3
+
+func formatAccountList(addresses []string) string {
+	if len(addresses) < 1 {
+		return "0"
+	}
+
+	var l []string
+	for i, address := range addresses {
+		if i == numberOfListedAccounts {
+			l = append(l, "...")
+			break
+		}
+		l = append(l, formatAddress(address))
+	}
+
+	return fmt.Sprintf("%d; %s", len(addresses), strings.Join(l, ", "))
+}
+
+func (r *resultReport) addAccounts(a analysis.AccountReport) {
+	if len(a.Accounts) < 1 {
+		return
+	}
+
+	r.add("account", "# accounts", len(a.Accounts))
+	r.add("account", "# sources", a.Sources)
+	r.add("account", "fairness index", a.Fairness, fmt.Sprintf("%.5f", a.Fairness))
+	ratio := "-"
+	if a.MinRequests > 0 {
+		ratio = fmt.Sprintf("%.2f", float64(a.MaxRequests)/float64(a.MinRequests))
+	}
+	r.add(
+		"account",
+		"requests per account",
+		[]interface{}{a.MinRequests, a.MeanRequests, a.MaxRequests},
+		fmt.Sprintf("min %d | mean %.2f | max %d | max/min %s", a.MinRequests, a.MeanRequests, a.MaxRequests, ratio),
+	)
+	r.add("account", "never source", a.NeverSource, formatAccountList(a.NeverSource))
+	r.add("account", "never target", a.NeverTarget, formatAccountList(a.NeverTarget))
+	r.add("account", "stopped early", a.Stopped, formatAccountList(a.Stopped))
+
+	accounts := a.Accounts
+	var omitted int
+	if len(accounts) > numberOfAccountRows {
+		omitted = len(accounts) - numberOfAccountRows
+		accounts = append(
+			append([]analysis.AccountSummary{}, a.Accounts[:numberOfAccountRows/2]...),
+			a.Accounts[len(a.Accounts)-numberOfAccountRows/2:]...,
+		)
+	}
+	for i, s := range accounts {
+		if omitted > 0 && i == numberOfAccountRows/2 {
+			r.add("account-requests", "...", omitted, fmt.Sprintf("%d accounts", omitted))
+		}
+		r.add("account-requests", formatAddress(s.Address), s, s.String())
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var chartColors = []color.NRGBA{
+	{R: 0x4e, G: 0x79, B: 0xa7, A: 0xff},
+	{R: 0xf2, G: 0x8e, B: 0x2b, A: 0xff},
+	{R: 0xe1, G: 0x57, B: 0x59, A: 0xff},
+	{R: 0x76, G: 0xb7, B: 0xb2, A: 0xff},
+	{R: 0x59, G: 0xa1, B: 0x4f, A: 0xff},
+	{R: 0xed, G: 0xc9, B: 0x48, A: 0xff},
+	{R: 0xb0, G: 0x7a, B: 0xa1, A: 0xff},
+	{R: 0xff, G: 0x9d, B: 0xa7, A: 0xff},
+}
+
+const (
+	chartWidth   vg.Length = 10 * vg.Inch
+	chartHeight  vg.Length = 4 * vg.Inch
+	maxBarLabels int       = 20
+)
+
+// chartColor returns the color of i'th series; alpha is from 0 to 1.
+func chartColor(i int, alpha float64) color.NRGBA {
+	c := chartColors[i%len(chartColors)]
+	c.A = uint8(alpha * 0xff)
+
+	return c
+}
+
+type chartSeries struct {
+	Name   string
+	Points [][2]float64
+}
+
+func (s chartSeries) XYs() plotter.XYs {
+	xys := make(plotter.XYs, len(s.Points))
+	for i, p := range s.Points {
+		xys[i].X, xys[i].Y = p[0], p[1]
+	}
+
+	return xys
+}
+
+// chartTicks formats the labels of default ticks.
+type chartTicks struct {
+	format func(float64) string
+}
+
+func (t chartTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := plot.DefaultTicks{}.Ticks(min, max)
+	for i := range ticks {
+		if len(ticks[i].Label) > 0 {
+			ticks[i].Label = t.format(ticks[i].Value)
+		}
+	}
+
+	return ticks
+}
+
+// chart draws the chart by gonum plot, it can be rendered into SVG and PNG.
+type chart struct {
+	Title   string
+	XLabel  string
+	YLabel  string
+	XFormat func(float64) string
+	YFormat func(float64) string
+
+	p *plot.Plot
+}
+
+func newChart(title, xLabel, yLabel string) *chart {
+	format := func(v float64) string {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	return &chart{
+		Title:   title,
+		XLabel:  xLabel,
+		YLabel:  yLabel,
+		XFormat: format,
+		YFormat: format,
+	}
+}
+
+func (c *chart) begin() *plot.Plot {
+	p := plot.New()
+	p.Title.Text = c.Title
+	p.X.Label.Text = c.XLabel
+	p.Y.Label.Text = c.YLabel
+	p.X.Tick.Marker = chartTicks{format: c.XFormat}
+	p.Y.Tick.Marker = chartTicks{format: c.YFormat}
+	p.Y.Min = 0
+	p.Legend.Top = true
+	p.Add(plotter.NewGrid())
+
+	c.p = p
+
+	return p
+}
+
+func (c *chart) line(i int, s chartSeries) {
+	if len(s.Points) < 1 {
+		return
+	}
+
+	l, err := plotter.NewLine(s.XYs())
+	if err != nil {
+		log.Error("failed to draw line", "series", s.Name, "error", err)
+		return
+	}
+	l.LineStyle.Color = chartColor(i, 1)
+	l.LineStyle.Width = vg.Points(1.5)
+
+	c.p.Add(l)
+	c.p.Legend.Add(s.Name, l)
+}
+
+func (c *chart) write(w io.Writer, format string) (err error) {
+	if c.p == nil {
+		c.begin()
+	}
+
+	var wt io.WriterTo
+	if wt, err = c.p.WriterTo(chartWidth, chartHeight, format); err != nil {
+		return
+	}
+	_, err = wt.WriteTo(w)
+
+	return
+}
+
+// SVG returns the SVG element without XML declaration, so it can be embedded
+// in HTML.
+func (c *chart) SVG() string {
+	b := bytes.NewBuffer(nil)
+	if err := c.write(b, "svg"); err != nil {
+		log.Error("failed to render chart", "chart", c.Title, "error", err)
+		return ""
+	}
+
+	s := b.String()
+	if i := strings.Index(s, "<svg"); i > 0 {
+		s = s[i:]
+	}
+
+	return s
+}
+
+func (c *chart) PNG(w io.Writer) error {
+	return c.write(w, "png")
+}
+
+// Line draws the line chart of series.
+func (c *chart) Line(series ...chartSeries) string {
+	c.PlotLine(series...)
+
+	return c.SVG()
+}
+
+func (c *chart) PlotLine(series ...chartSeries) {
+	c.begin()
+
+	for i, s := range series {
+		c.line(i, s)
+	}
+}
+
+// PlotScatter draws the points of series as dots; the dots are translucent,
+// so the dense area looks darker.
+func (c *chart) PlotScatter(series ...chartSeries) {
+	c.begin()
+
+	for i, s := range series {
+		if len(s.Points) < 1 {
+			continue
+		}
+
+		sc, err := plotter.NewScatter(s.XYs())
+		if err != nil {
+			log.Error("failed to draw scatter", "series", s.Name, "error", err)
+			continue
+		}
+		sc.GlyphStyle.Color = chartColor(i, 0.4)
+		sc.GlyphStyle.Radius = vg.Points(1.5)
+		sc.GlyphStyle.Shape = draw.CircleGlyph{}
+
+		c.p.Add(sc)
+		c.p.Legend.Add(s.Name, sc)
+	}
+}
+
+// PlotBands fills the area between the adjacent series and draws the lines
+// of series; the first band is from 0 to the first series. The series must
+// have the same x values.
+func (c *chart) PlotBands(series ...chartSeries) {
+	c.begin()
+
+	for i, s := range series {
+		if len(s.Points) < 1 {
+			continue
+		}
+
+		band := s.XYs()
+		for j := len(s.Points) - 1; j >= 0; j-- {
+			var lower float64
+			if i > 0 && j < len(series[i-1].Points) {
+				lower = series[i-1].Points[j][1]
+			}
+			band = append(band, plotter.XY{X: s.Points[j][0], Y: lower})
+		}
+
+		polygon, err := plotter.NewPolygon(band)
+		if err != nil {
+			log.Error("failed to draw band", "series", s.Name, "error", err)
+			continue
+		}
+		polygon.Color = chartColor(i, 0.25)
+		polygon.LineStyle.Width = 0
+
+		c.p.Add(polygon)
+	}
+
+	for i, s := range series {
+		c.line(i, s)
+	}
+}
+
+// Bar draws the bar chart; every value has it's own label.
+func (c *chart) Bar(labels []string, values []float64) string {
+	c.PlotBar(labels, values)
+
+	return c.SVG()
+}
+
+func (c *chart) PlotBar(labels []string, values []float64) {
+	c.begin()
+
+	if len(values) < 1 {
+		return
+	}
+
+	width := (chartWidth - vg.Inch) / vg.Length(len(values)) * 0.6
+	bar, err := plotter.NewBarChart(plotter.Values(values), width)
+	if err != nil {
+		log.Error("failed to draw bar", "error", err)
+		return
+	}
+	bar.Color = chartColor(0, 1)
+	bar.LineStyle.Width = 0
+
+	c.p.Add(bar)
+	// NOTE every bar is in the middle of it's slot
+	c.p.X.Min, c.p.X.Max = -0.5, float64(len(values))-0.5
+
+	// NOTE over maxBarLabels, the labels are too dense to read
+	if len(labels) > maxBarLabels {
+		c.p.X.Tick.Marker = plot.ConstantTicks(nil)
+		return
+	}
+	c.p.NominalX(labels...)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+// maxScatterPoints limits the dots of latency scatter; over it, the records
+// are sampled evenly.
+const maxScatterPoints int = 20000
+
+// numberOfChartWindows is the number of windows in the charts over time, when
+// `--window` is not given.
+const numberOfChartWindows int = 60
+
+func parseChartsFormat(s string) (formats []string, err error) {
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		switch f {
+		case "svg", "png":
+			formats = append(formats, f)
+		case "":
+		default:
+			err = fmt.Errorf("unknown format, '%s'", f)
+			return
+		}
+	}
+
+	if len(formats) < 1 {
+		err = fmt.Errorf("empty format")
+	}
+
+	return
+}
+
+// chartWindow returns the window of the charts over time; the first of
+// `--window` or about 1/60 of total elapsed time in seconds.
+func chartWindow(report *resultReport) time.Duration {
+	if len(windows) > 0 {
+		return windows[0]
+	}
+
+	window := (report.TotalElapsed / time.Duration(numberOfChartWindows)).Truncate(time.Second)
+	if window < time.Second {
+		window = time.Second
+	}
+
+	return window
+}
+
+func formatChartSeconds(v float64) string {
+	return fmt.Sprintf("%.1f", v)
+}
+
+// newResultCharts draws the charts of report; the key is the file name
+// without extension.
+func newResultCharts(report *resultReport) (names []string, charts map[string]*chart) {
+	charts = map[string]*chart{}
+	add := func(name string, c *chart) {
+		names = append(names, name)
+		charts[name] = c
+	}
+
+	{
+		var confirmed, failed [][2]float64
+		step := len(report.Records)/maxScatterPoints + 1
+		for i := 0; i < len(report.Records); i += step {
+			r := report.Records[i]
+			elapsed := time.Duration(r.GetElapsed())
+			x := r.GetTime().Add(-elapsed).Sub(report.Started).Seconds()
+			if r.GetError() == nil {
+				confirmed = append(confirmed, [2]float64{x, elapsed.Seconds()})
+			} else {
+				failed = append(failed, [2]float64{x, elapsed.Seconds()})
+			}
+		}
+
+		title := "elapsed time of requests"
+		if step > 1 {
+			title = fmt.Sprintf("%s, 1 of every %d requests", title, step)
+		}
+		c := newChart(title, "seconds from started", "elapsed")
+		c.XFormat = formatChartSeconds
+		c.YFormat = durationSeconds
+		c.PlotScatter(
+			chartSeries{Name: "confirmed", Points: confirmed},
+			chartSeries{Name: "error", Points: failed},
+		)
+		add("latency-scatter", c)
+	}
+
+	window := chartWindow(report)
+	ts := newTimeSeries(window, report.Started, report.Records)
+	{
+		var p50, p90, p99 [][2]float64
+		for _, p := range ts.Points {
+			// NOTE the window without any request is skipped instead of 0
+			if p.P99 < 1 {
+				continue
+			}
+
+			x := p.Offset.Seconds()
+			p50 = append(p50, [2]float64{x, p.P50.Seconds()})
+			p90 = append(p90, [2]float64{x, p.P90.Seconds()})
+			p99 = append(p99, [2]float64{x, p.P99.Seconds()})
+		}
+
+		c := newChart(fmt.Sprintf("elapsed time percentiles, window: %v", window), "seconds from started", "elapsed")
+		c.XFormat = formatChartSeconds
+		c.YFormat = durationSeconds
+		c.PlotBands(
+			chartSeries{Name: "p50", Points: p50},
+			chartSeries{Name: "p90", Points: p90},
+			chartSeries{Name: "p99", Points: p99},
+		)
+		add("latency-percentiles", c)
+	}
+
+	{
+		var submitted, tps [][2]float64
+		for _, p := range ts.Points {
+			x := p.Offset.Seconds()
+			submitted = append(submitted, [2]float64{x, float64(p.Submitted) / window.Seconds()})
+			tps = append(tps, [2]float64{x, p.TPS})
+		}
+
+		c := newChart(fmt.Sprintf("throughput, window: %v", window), "seconds from started", "per second")
+		c.XFormat = formatChartSeconds
+		c.PlotLine(
+			chartSeries{Name: "TPS", Points: tps},
+			chartSeries{Name: "submitted", Points: submitted},
+		)
+		add("throughput", c)
+	}
+
+	{
+		var keys []string
+		for errorType := range report.ErrorTypes {
+			keys = append(keys, string(errorType))
+		}
+		sortByCategory(keys, func(k string) hotbody.ErrorCategory {
+			return report.ErrorInfos[hotbody.RecordErrorType(k)].Category
+		})
+
+		var values []float64
+		for _, k := range keys {
+			values = append(values, analysis.ErrorRatio(report.ErrorTypes[hotbody.RecordErrorType(k)], report.Requests)*100)
+		}
+
+		title := "error rate by type"
+		if len(keys) < 1 {
+			title += ", no error"
+		}
+		c := newChart(title, "error type", "%")
+		c.YFormat = func(v float64) string {
+			return fmt.Sprintf("%.3g%%", v)
+		}
+		c.PlotBar(keys, values)
+		add("error-rate", c)
+	}
+
+	{
+		var labels []string
+		var values []float64
+		for _, b := range report.Distribution {
+			labels = append(labels, b.Low.String())
+			values = append(values, float64(b.Count))
+		}
+
+		c := newChart(
+			fmt.Sprintf("elapsed time distribution, bucket: %v", report.BucketWidth),
+			"elapsed time",
+			"# requests",
+		)
+		c.PlotBar(labels, values)
+		add("latency-histogram", c)
+	}
+
+	return
+}
+
+// writeCharts writes the charts into directory in the formats.
+func writeCharts(dir string, formats []string, report *resultReport) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	names, charts := newResultCharts(report)
+	for _, name := range names {
+		for _, format := range formats {
+			if err = writeChart(filepath.Join(dir, name+"."+format), format, charts[name]); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func writeChart(f, format string, c *chart) (err error) {
+	var w *os.File
+	if w, err = os.Create(f); err != nil {
+		return
+	}
+	defer w.Close()
+
+	switch format {
+	case "png":
+		err = c.PNG(w)
+	default:
+		_, err = fmt.Fprintln(w, c.SVG())
+	}
+
+	if err != nil {
+		return
+	}
+
+	log.Debug("chart written", "file", f)
+
+	return
+}
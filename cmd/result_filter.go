@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"boscoin.io/sebak/lib/common"
+	"github.com/spf13/cobra"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+var filter analysis.Filter
+
+func addResultFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flagSince, "since", flagSince, "exclude the records before it, duration from started or time, '30s', '2018-11-04T16:36:35Z'")
+	cmd.Flags().StringVar(&flagUntil, "until", flagUntil, "exclude the records after it, duration from started or time, '3m', '2018-11-04T16:39:35Z'")
+	cmd.Flags().StringVar(&flagSkipWarmup, "skip-warmup", flagSkipWarmup, "exclude the records within duration after started")
+	cmd.Flags().StringVar(&flagSkipCooldown, "skip-cooldown", flagSkipCooldown, "exclude the records within duration before the end of testing time")
+	cmd.Flags().StringVar(&flagPhase, "phase", flagPhase, "include only the records of phases, comma separated, {provisioning, running, drain}")
+}
+
+func parseResultFilterFlags(cmd *cobra.Command) {
+	var err error
+	if filter.Since, err = parseResultTimeFlag(flagSince); err != nil {
+		printFlagsError(cmd, "--since", err)
+	}
+	if filter.Until, err = parseResultTimeFlag(flagUntil); err != nil {
+		printFlagsError(cmd, "--until", err)
+	}
+	if len(flagSkipWarmup) > 0 {
+		if filter.SkipWarmup, err = time.ParseDuration(flagSkipWarmup); err != nil {
+			printFlagsError(cmd, "--skip-warmup", err)
+		}
+	}
+	if len(flagSkipCooldown) > 0 {
+		if filter.SkipCooldown, err = time.ParseDuration(flagSkipCooldown); err != nil {
+			printFlagsError(cmd, "--skip-cooldown", err)
+		}
+	}
+	if filter.Phases, err = parsePhases(flagPhase); err != nil {
+		printFlagsError(cmd, "--phase", err)
+	}
+}
+
+// parseResultTimeFlag parses the time of `--since` and `--until`; the time
+// or the duration from `started`.
+func parseResultTimeFlag(s string) (t analysis.FilterTime, err error) {
+	if len(s) < 1 {
+		return
+	}
+	t.IsSet = true
+
+	if t.Offset, err = time.ParseDuration(s); err == nil {
+		return
+	}
+
+	if t.Time, err = time.Parse(time.RFC3339Nano, s); err == nil {
+		return
+	}
+	if t.Time, err = common.ParseISO8601(s); err == nil {
+		return
+	}
+
+	err = fmt.Errorf("duration from started or time is expected, '%s'", s)
+
+	return
+}
+
+func parsePhases(s string) (phases []string, err error) {
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if len(p) < 1 {
+			continue
+		}
+
+		var found bool
+		for _, k := range analysis.Phases {
+			if p == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = fmt.Errorf("unknown phase, '%s'", p)
+			return
+		}
+		phases = append(phases, p)
+	}
+
+	return
+}
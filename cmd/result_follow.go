@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	isatty "github.com/mattn/go-isatty"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+var (
+	followInterval time.Duration
+	followWindow   time.Duration
+)
+
+// resultFollower reads the <result log>, which is still being written by
+// `go`; the incomplete last line is kept until it is completed.
+type resultFollower struct {
+	file    string
+	f       *os.File
+	r       *bufio.Reader
+	partial string
+	rl      analysis.Log
+	loaded  bool // NOTE config is loaded
+}
+
+// read reads the new lines of <result log>; it returns true, when `ended`
+// record is found.
+func (f *resultFollower) read() (ended bool, err error) {
+	if f.f == nil {
+		if f.f, err = os.Open(f.file); os.IsNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return
+		}
+		f.r = bufio.NewReader(f.f)
+	}
+
+	for {
+		var line string
+		line, err = f.r.ReadString('\n')
+		if err == io.EOF {
+			f.partial += line
+			return false, nil
+		} else if err != nil {
+			return
+		}
+
+		line = strings.TrimSpace(f.partial + line)
+		f.partial = ""
+		if len(line) < 1 {
+			continue
+		}
+
+		if !f.loaded {
+			if err = f.rl.LoadConfig(line); err != nil {
+				return
+			}
+			f.loaded = true
+			continue
+		}
+
+		if err = f.rl.LoadRecord(line); err != nil {
+			return
+		}
+		if !f.rl.Ended.IsZero() {
+			return true, nil
+		}
+	}
+}
+
+func (f *resultFollower) close() {
+	if f.f != nil {
+		f.f.Close()
+	}
+}
+
+// recentSummary is the statistics of the records in the recent window
+// before the last record.
+type recentSummary struct {
+	Window     time.Duration `json:"window"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	ErrorRate  float64       `json:"error-rate"`
+	TPS        float64       `json:"tps"`
+	OPS        float64       `json:"ops"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	LastRecord time.Time     `json:"last-record"`
+}
+
+func newRecentSummary(rl analysis.Log, window time.Duration) (s recentSummary) {
+	s.Window = window
+	if len(rl.Records) < 1 {
+		return
+	}
+
+	s.LastRecord = rl.Records[len(rl.Records)-1].GetTime()
+	since := s.LastRecord.Add(-window)
+
+	h := hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+	var operations int
+	for i := len(rl.Records) - 1; i >= 0; i-- {
+		r := rl.Records[i]
+		if r.GetTime().Before(since) {
+			break
+		}
+
+		s.Requests++
+		h.Record(r.GetElapsed())
+		if r.GetError() != nil {
+			s.Errors++
+			continue
+		}
+		operations += analysis.RecordOperations(r, rl.Config.Operations)
+	}
+
+	s.ErrorRate = analysis.ErrorRatio(s.Errors, s.Requests)
+	s.TPS = float64(s.Requests-s.Errors) / window.Seconds()
+	s.OPS = float64(operations) / window.Seconds()
+	s.P50 = time.Duration(h.ValueAtPercentile(50))
+	s.P90 = time.Duration(h.ValueAtPercentile(90))
+	s.P99 = time.Duration(h.ValueAtPercentile(99))
+	s.Max = time.Duration(h.Max())
+
+	return
+}
+
+func (r *resultReport) addRecent(s recentSummary) {
+	section := fmt.Sprintf("recent %v", s.Window)
+	r.add(section, "last record", s.LastRecord, FormatISO8601(s.LastRecord))
+	r.add(section, "# requests", s.Requests)
+	r.add(
+		section,
+		"error rates",
+		s.ErrorRate,
+		fmt.Sprintf("%2.5f％ (%d/%d)", s.ErrorRate*100, s.Errors, s.Requests),
+	)
+	r.add(section, "TPS", s.TPS, fmt.Sprintf("%.2f", s.TPS))
+	r.add(section, "OPS", s.OPS, fmt.Sprintf("%.2f", s.OPS))
+	r.add(section, "p50 elapsed time", s.P50)
+	r.add(section, "p90 elapsed time", s.P90)
+	r.add(section, "p99 elapsed time", s.P99)
+	r.add(section, "max elapsed time", s.Max)
+}
+
+// followResult tails the <result log> and renders the cumulative result and
+// the statistics of recent window for every interval until `ended` record
+// is found. In terminal, the screen is cleared before rendering.
+func followResult(file string, w io.Writer) (report *resultReport, err error) {
+	f := &resultFollower{file: file}
+	defer f.close()
+
+	clear := isatty.IsTerminal(os.Stdout.Fd()) && flagFormat == "terminal"
+	render := func(ended bool) (err error) {
+		b := bytes.NewBuffer(nil)
+		if clear {
+			fmt.Fprint(b, "\x1b[H\x1b[2J")
+		}
+
+		// NOTE except the terminal format, the state is printed to stderr not
+		// to break the output.
+		var state io.Writer = b
+		if flagFormat != "terminal" {
+			state = os.Stderr
+		}
+
+		if ended {
+			fmt.Fprintf(state, "ended %s, %s\n", file, FormatISO8601(time.Now()))
+		} else {
+			fmt.Fprintf(state, "following %s, %s\n", file, FormatISO8601(time.Now()))
+		}
+
+		switch {
+		case !f.loaded:
+			fmt.Fprintln(state, "waiting for config")
+		case len(f.rl.Records) < 1:
+			fmt.Fprintf(
+				state,
+				"waiting for payment records; %d transactions for creating accounts\n",
+				len(f.rl.CreateAccounts),
+			)
+		default:
+			var rl analysis.Log
+			if rl, err = analysis.FilterLog(f.rl, filter); err == analysis.ErrNoRecords {
+				fmt.Fprintln(state, "waiting for payment records in window")
+				err = nil
+				break
+			} else if err != nil {
+				return
+			}
+
+			report = newResultReport(rl)
+			if !ended {
+				report.addRecent(newRecentSummary(rl, followWindow))
+			}
+			if err = report.Render(b, flagFormat); err != nil {
+				return
+			}
+		}
+
+		_, err = io.Copy(w, b)
+
+		return
+	}
+
+	for {
+		var ended bool
+		if ended, err = f.read(); err != nil {
+			return
+		}
+
+		if ended {
+			if err = render(true); err != nil {
+				return
+			}
+			if report == nil {
+				err = analysis.ErrNoRecords
+			}
+			return
+		}
+
+		if err = render(false); err != nil {
+			return
+		}
+
+		time.Sleep(followInterval)
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"time"
+)
+
+const resultHTMLStyle string = `
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ddd; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { border: 1px solid #ddd; padding: 3px 10px; font-size: 0.9em; }
+td.value { text-align: right; font-family: monospace; }
+th { background: #f5f5f5; text-align: left; }
+`
+
+func durationSeconds(v float64) string {
+	return time.Duration(v * float64(time.Second)).Truncate(time.Millisecond).String()
+}
+
+// renderHTML renders the self-contained single html report; the charts are
+// embedded as SVG.
+func (r *resultReport) renderHTML(w io.Writer) error {
+	e := html.EscapeString
+
+	fmt.Fprintln(w, `<!DOCTYPE html>`)
+	fmt.Fprintln(w, `<html><head><meta charset="utf-8"><title>sebak-hot-body result</title>`)
+	fmt.Fprintf(w, "<style>%s</style>\n", resultHTMLStyle)
+	fmt.Fprintln(w, `</head><body>`)
+	fmt.Fprintln(w, `<h1>sebak-hot-body result</h1>`)
+
+	for _, section := range r.Sections {
+		fmt.Fprintf(w, "<h2>%s</h2>\n<table>\n", e(section.Name))
+		for _, row := range section.Rows {
+			fmt.Fprintf(w, "<tr><th>%s</th><td class=\"value\">%s</td></tr>\n", e(row.Key), e(row.String()))
+		}
+		fmt.Fprintln(w, `</table>`)
+
+		if section.Name == "result" {
+			r.renderHTMLCharts(w)
+		}
+	}
+
+	for _, ts := range r.TimeSeries {
+		fmt.Fprintf(w, "<h2>time series, window: %s</h2>\n", e(ts.Window.String()))
+
+		var tps, errorRate, p50, p90, p99 [][2]float64
+		for _, p := range ts.Points {
+			x := p.Offset.Seconds()
+			tps = append(tps, [2]float64{x, p.TPS})
+			errorRate = append(errorRate, [2]float64{x, p.ErrorRate * 100})
+			p50 = append(p50, [2]float64{x, p.P50.Seconds()})
+			p90 = append(p90, [2]float64{x, p.P90.Seconds()})
+			p99 = append(p99, [2]float64{x, p.P99.Seconds()})
+		}
+
+		c := newChart("throughput", "seconds from started", "TPS")
+		fmt.Fprintln(w, c.Line(chartSeries{Name: "TPS", Points: tps}))
+
+		c = newChart("elapsed time", "seconds from started", "elapsed")
+		c.YFormat = durationSeconds
+		fmt.Fprintln(w, c.Line(
+			chartSeries{Name: "p50", Points: p50},
+			chartSeries{Name: "p90", Points: p90},
+			chartSeries{Name: "p99", Points: p99},
+		))
+
+		c = newChart("error rate", "seconds from started", "%")
+		fmt.Fprintln(w, c.Line(chartSeries{Name: "error rate", Points: errorRate}))
+
+		fmt.Fprintln(w, `<table>`)
+		fmt.Fprintln(w, `<tr><th>offset</th><th>submitted</th><th>confirmed</th><th>operations</th><th>errors</th><th>error rate</th><th>p50</th><th>p90</th><th>p99</th></tr>`)
+		for _, p := range ts.Points {
+			fmt.Fprintf(
+				w,
+				"<tr><td class=\"value\">%v</td><td class=\"value\">%d</td><td class=\"value\">%d</td><td class=\"value\">%d</td><td class=\"value\">%d</td><td class=\"value\">%.5f％</td><td class=\"value\">%v</td><td class=\"value\">%v</td><td class=\"value\">%v</td></tr>\n",
+				p.Offset,
+				p.Submitted,
+				p.Confirmed,
+				p.Operations,
+				p.Errors,
+				p.ErrorRate*100,
+				p.P50.Truncate(time.Millisecond),
+				p.P90.Truncate(time.Millisecond),
+				p.P99.Truncate(time.Millisecond),
+			)
+		}
+		fmt.Fprintln(w, `</table>`)
+	}
+
+	fmt.Fprintln(w, `</body></html>`)
+
+	return nil
+}
+
+func (r *resultReport) renderHTMLCharts(w io.Writer) {
+	var labels []string
+	var values []float64
+	for _, b := range r.Distribution {
+		labels = append(labels, b.Low.String())
+		values = append(values, float64(b.Count))
+	}
+
+	c := newChart(
+		fmt.Sprintf("elapsed time distribution, bucket: %v", r.BucketWidth),
+		"elapsed time",
+		"# requests",
+	)
+	fmt.Fprintln(w, c.Bar(labels, values))
+
+	labels, values = nil, nil
+	for _, p := range percentiles {
+		labels = append(labels, fmt.Sprintf("p%v", p))
+		values = append(values, time.Duration(r.Histogram.ValueAtPercentile(p)).Seconds())
+	}
+
+	c = newChart("elapsed time percentiles", "percentile", "elapsed")
+	c.YFormat = durationSeconds
+	fmt.Fprintln(w, c.Bar(labels, values))
+}
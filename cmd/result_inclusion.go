@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+func (r *resultReport) addInclusion(i analysis.InclusionReport) {
+	// NOTE the old <result log> does not have block heights
+	if i.Payments < 1 {
+		return
+	}
+
+	r.add("inclusion", "# payments with block height", i.Payments)
+	r.add(
+		"inclusion",
+		"missed next block",
+		i.MissedRatio,
+		fmt.Sprintf("%2.5f％ (%d/%d)", i.MissedRatio*100, i.MissedNextBlock, i.Payments),
+	)
+	r.add("inclusion", "p50 blocks", i.P50)
+	r.add("inclusion", "p99 blocks", i.P99)
+	r.add("inclusion", "max blocks", i.Max)
+
+	for _, b := range i.Distribution {
+		key := fmt.Sprintf("%d blocks", b.Blocks)
+		switch {
+		case b.Overflow:
+			key = fmt.Sprintf("≥%d blocks", b.Blocks)
+		case b.Blocks == 1:
+			key = "1 block"
+		}
+		r.add("inclusion", key, b, fmt.Sprintf("%9.5f％ / %5d", b.Ratio*100, b.Count))
+	}
+}
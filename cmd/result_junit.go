@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+const junitName string = "sebak-hot-body"
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr,omitempty"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	tc.ClassName = s.Name
+	s.TestCases = append(s.TestCases, tc)
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+}
+
+func junitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+// newJUnitReport builds the JUnit report from result; it has the test suites,
+//
+// `phases`: one test case for each phase, `create-accounts` and `payment`.
+//
+// `assertions`: one test case for each assertion.
+//
+// `errors`: one test case for each error type and SEBAK error code with the
+// sample transactions. It fails only when the assertion of it's metric, like
+// `error_rate.sebak-134` fails.
+func newJUnitReport(report *resultReport, results []assertionResult) junitTestSuites {
+	timestamp := FormatISO8601(report.Started)
+
+	phases := junitTestSuite{Name: junitName + ".phases", Timestamp: timestamp}
+	{
+		tc := junitTestCase{Name: "create-accounts"}
+		tc.SystemOut = &junitOutput{Text: fmt.Sprintf(
+			"transactions: %d\naccounts: %d\nerrors: %d\n",
+			report.Provisioning.Transactions,
+			report.Provisioning.Accounts,
+			report.Provisioning.Errors,
+		)}
+		if report.Provisioning.Errors > 0 {
+			tc.Failure = &junitFailure{
+				Type:    "create-accounts",
+				Message: fmt.Sprintf("%d of %d transactions failed", report.Provisioning.Errors, report.Provisioning.Transactions),
+			}
+		}
+		phases.add(tc)
+	}
+	{
+		tc := junitTestCase{Name: "payment", Time: junitSeconds(report.TotalElapsed.Seconds())}
+
+		var out []string
+		for _, m := range report.Metrics() {
+			out = append(out, fmt.Sprintf("%s: %s", m.Name, m.String()))
+		}
+		tc.SystemOut = &junitOutput{Text: strings.Join(out, "\n") + "\n"}
+
+		if report.Requests <= report.Errors {
+			tc.Failure = &junitFailure{
+				Type:    "payment",
+				Message: fmt.Sprintf("no transaction confirmed in %d requests", report.Requests),
+			}
+		}
+		phases.add(tc)
+	}
+	phases.Time = junitSeconds(report.TotalElapsed.Seconds())
+
+	failedMetrics := map[string]bool{}
+	asserted := junitTestSuite{Name: junitName + ".assertions", Timestamp: timestamp}
+	for _, r := range results {
+		tc := junitTestCase{
+			Name:      r.Rule,
+			SystemOut: &junitOutput{Text: fmt.Sprintf("actual: %s\n", r.ActualString())},
+		}
+		if !r.Passed {
+			failedMetrics[r.Metric] = true
+			tc.Failure = &junitFailure{
+				Type: "assertion",
+				Message: fmt.Sprintf(
+					"%s; actual %s: %s",
+					r.Rule,
+					r.Metric,
+					r.ActualString(),
+				),
+			}
+		}
+		asserted.add(tc)
+	}
+
+	errorSuite := junitTestSuite{Name: junitName + ".errors", Timestamp: timestamp}
+	errorCase := func(metric string, count int, ratio float64, info hotbody.ErrorInfo, samples []string) {
+		tc := junitTestCase{
+			Name: metric,
+			SystemOut: &junitOutput{Text: fmt.Sprintf(
+				"count: %d\nratio: %.5f％\nname: %s\ncategory: %s\nretryable: %v\nsample transactions:\n%s\n",
+				count,
+				ratio*100,
+				info.Name,
+				info.Category,
+				info.Retryable,
+				strings.Join(samples, "\n"),
+			)},
+		}
+		if failedMetrics[metric] {
+			tc.Failure = &junitFailure{
+				Type:    "error",
+				Message: fmt.Sprintf("%d errors; samples: %s", count, strings.Join(samples, ", ")),
+			}
+		}
+		errorSuite.add(tc)
+	}
+
+	var errorTypes []string
+	for errorType := range report.ErrorTypes {
+		errorTypes = append(errorTypes, string(errorType))
+	}
+	sort.Strings(errorTypes)
+	for _, k := range errorTypes {
+		errorType := hotbody.RecordErrorType(k)
+		errorCase(
+			"error_rate."+k,
+			report.ErrorTypes[errorType],
+			float64(report.ErrorTypes[errorType])/float64(report.Requests),
+			report.ErrorInfos[errorType],
+			report.ErrorSamples[errorType],
+		)
+	}
+
+	var countSEBAKError int
+	var codes []int
+	for code, count := range report.SEBAKErrors {
+		countSEBAKError += count
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		errorCase(
+			fmt.Sprintf("sebak_error.%d", code),
+			report.SEBAKErrors[code],
+			float64(report.SEBAKErrors[code])/float64(countSEBAKError),
+			report.SEBAKErrorInfos[code],
+			report.SEBAKErrorSamples[code],
+		)
+	}
+
+	suites := junitTestSuites{Name: junitName}
+	for _, s := range []junitTestSuite{phases, asserted, errorSuite} {
+		if s.Tests < 1 {
+			continue
+		}
+		suites.TestSuites = append(suites.TestSuites, s)
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+	}
+
+	return suites
+}
+
+func writeJUnit(w io.Writer, suites junitTestSuites) error {
+	b, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprint(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
+}
+
+func writeJUnitFile(f string, report *resultReport) (err error) {
+	results, _ := evaluateAssertions(report)
+
+	var w *os.File
+	if w, err = os.Create(f); err != nil {
+		return
+	}
+	defer w.Close()
+
+	return writeJUnit(w, newJUnitReport(report, results))
+}
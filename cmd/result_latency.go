@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+func formatLatency(h *hotbody.Histogram) string {
+	return fmt.Sprintf(
+		"p50 %v | p90 %v | p99 %v | max %v",
+		time.Duration(h.ValueAtPercentile(50)).Truncate(time.Microsecond),
+		time.Duration(h.ValueAtPercentile(90)).Truncate(time.Microsecond),
+		time.Duration(h.ValueAtPercentile(99)).Truncate(time.Microsecond),
+		time.Duration(h.Max()).Truncate(time.Microsecond),
+	)
+}
+
+func (r *resultReport) addLatencyBreakdown(b analysis.LatencyBreakdown) {
+	// NOTE the old <result log> does not have timings
+	if b.Records < 1 {
+		return
+	}
+
+	r.add("latency", "# payments with timings", b.Records)
+	r.add("latency", "signing", b.Signing, formatLatency(b.Signing))
+	r.add("latency", "submit", b.Submit, formatLatency(b.Submit))
+	r.add("latency", "confirmation", b.Confirmation, formatLatency(b.Confirmation))
+	r.add("latency", "polling overhead", b.PollingOverhead, formatLatency(b.PollingOverhead))
+	r.add(
+		"latency",
+		"submit share",
+		b.SubmitShare,
+		fmt.Sprintf("%.5f％ of submit + confirmation", b.SubmitShare*100),
+	)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+// expandResultFiles expands the glob patterns of <result log>; the pattern,
+// which matches nothing, is kept as it is, so it fails when it is opened.
+func expandResultFiles(args []string) (files []string, err error) {
+	found := map[string]bool{}
+	for _, a := range args {
+		var matches []string
+		if matches, err = filepath.Glob(a); err != nil {
+			err = fmt.Errorf("invalid pattern, '%s'; %v", a, err)
+			return
+		}
+		if len(matches) < 1 {
+			matches = []string{a}
+		}
+
+		sort.Strings(matches)
+		for _, m := range matches {
+			if found[m] {
+				continue
+			}
+			found[m] = true
+			files = append(files, m)
+		}
+	}
+
+	return
+}
+
+// loadResultSource loads the <result log> as the source of merged result;
+// unlike loadResultFile, analysis.ErrNoRecords is returned as it is.
+func loadResultSource(f string) (rl analysis.Log, err error) {
+	var r *os.File
+	if r, err = os.Open(f); err != nil {
+		err = fmt.Errorf("failed to open <result log>; %v", err)
+		return
+	}
+	defer r.Close()
+
+	rl, err = analysis.Load(r)
+	rl.Source = f
+	if err == nil {
+		rl, err = analysis.FilterLog(rl, filter)
+	}
+	if err != nil && err != analysis.ErrNoRecords {
+		err = fmt.Errorf("%s: %v", f, err)
+	}
+
+	return
+}
+
+// loadResultFiles loads the <result log>s and merges them; the <result log>
+// without payment records is allowed, only if the other has.
+func loadResultFiles(files []string) (rl analysis.Log, err error) {
+	if len(files) == 1 {
+		return loadResultSource(files[0])
+	}
+
+	var logs []analysis.Log
+	for _, f := range files {
+		var l analysis.Log
+		if l, err = loadResultSource(f); err == analysis.ErrNoRecords {
+			log.Warn("no payment records", "file", f)
+		} else if err != nil {
+			return
+		}
+		logs = append(logs, l)
+	}
+	err = nil
+
+	return analysis.Merge(logs)
+}
+
+// sourceSummary is the short result of each source of merged result.
+type sourceSummary struct {
+	File       string        `json:"file"`
+	Endpoint   string        `json:"endpoint"`
+	T          int           `json:"t"`
+	Requests   int           `json:"requests"`
+	Operations int           `json:"operations"`
+	Errors     int           `json:"errors"`
+	ErrorRate  float64       `json:"error-rate"`
+	TPS        float64       `json:"tps"`
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+}
+
+func newSourceSummary(rl analysis.Log) (s sourceSummary) {
+	s.File = rl.Source
+	s.Endpoint = fmt.Sprintf("%v", rl.Config.Node.Node.Endpoint)
+	s.T = rl.Config.T
+	s.Requests = len(rl.Records)
+
+	if len(rl.Records) < 1 {
+		return
+	}
+
+	h := hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+	for _, r := range rl.Records {
+		h.Record(r.GetElapsed())
+		s.Operations += analysis.RecordOperations(r, rl.Config.Operations)
+		if r.GetError() != nil {
+			s.Errors++
+		}
+	}
+
+	s.ErrorRate = analysis.ErrorRatio(s.Errors, s.Requests)
+	s.P50 = time.Duration(h.ValueAtPercentile(50))
+	s.P99 = time.Duration(h.ValueAtPercentile(99))
+
+	if seconds := rl.Records[len(rl.Records)-1].GetTime().Sub(rl.Begin()).Seconds(); seconds > 0 {
+		s.TPS = float64(s.Requests-s.Errors) / seconds
+	}
+
+	return
+}
+
+func (s sourceSummary) String() string {
+	return fmt.Sprintf(
+		"%d requests | %.5f％ errors | %.2f TPS | p50 %v | p99 %v",
+		s.Requests,
+		s.ErrorRate*100,
+		s.TPS,
+		s.P50.Truncate(time.Millisecond),
+		s.P99.Truncate(time.Millisecond),
+	)
+}
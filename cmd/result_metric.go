@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+var errUnknownMetric = errors.New("unknown metric")
+
+var metricNamePrefixes = []string{
+	"error_rate.",
+	"error_category.",
+	"sebak_error.",
+}
+
+// metricKindOf returns the kind of the known metric name, which Metrics()
+// can have.
+func metricKindOf(name string) (kind metricKind, found bool) {
+	switch name {
+	case "requests", "operations", "provisioning_errors", "stopped_accounts", "inclusion_blocks_p50", "inclusion_blocks_p99":
+		return metricCount, true
+	case "tps", "expected_ops", "real_ops":
+		return metricRate, true
+	case "error_rate", "fairness", "missed_next_block":
+		return metricRatio, true
+	case "min", "mean", "max", "stddev", "setup_duration", "provisioning_p50", "provisioning_p99", "provisioning_max",
+		"submit_p50", "submit_p99", "confirm_p50", "confirm_p99", "polling_overhead_p50", "polling_overhead_p99":
+		return metricDuration, true
+	}
+
+	for _, p := range percentiles {
+		if fmt.Sprintf("p%v", p) == name {
+			return metricDuration, true
+		}
+	}
+
+	// NOTE the suffix must be the known error type, category or code, so the
+	// typo in metric name is not regarded as the absent metric
+	for _, prefix := range metricNamePrefixes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		suffix := name[len(prefix):]
+		switch prefix {
+		case "error_rate.":
+			return metricRatio, hotbody.IsKnownErrorType(hotbody.RecordErrorType(suffix))
+		case "error_category.":
+			return metricRatio, hotbody.ErrorCategory(suffix).IsValid()
+		case "sebak_error.":
+			code, err := strconv.Atoi(suffix)
+			return metricCount, err == nil && code > 0
+		}
+	}
+
+	return
+}
+
+func isMetricName(name string) bool {
+	_, found := metricKindOf(name)
+	return found
+}
+
+type metricKind string
+
+const (
+	metricCount    metricKind = "count"
+	metricRate     metricKind = "rate"     // NOTE per second
+	metricRatio    metricKind = "ratio"    // NOTE 0 to 1
+	metricDuration metricKind = "duration" // NOTE nanoseconds
+)
+
+// resultMetric is the single number of result, which can be compared with the
+// other result. NoData means the value is 0, because nothing was measured,
+// like the latency without confirmed payments.
+type resultMetric struct {
+	Name           string     `json:"name"`
+	Kind           metricKind `json:"kind"`
+	Value          float64    `json:"value"`
+	HigherIsBetter bool       `json:"higher-is-better"`
+	NoData         bool       `json:"no-data,omitempty"`
+}
+
+func (m resultMetric) String() string {
+	if m.NoData {
+		return "no data"
+	}
+
+	return formatMetricValue(m.Kind, m.Value)
+}
+
+// isCounterMetric checks whether the metric is the counter of errors, which
+// is not found in the result when nothing is counted, so it is regarded as 0.
+func isCounterMetric(name string) bool {
+	for _, prefix := range metricNamePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatMetricValue(kind metricKind, v float64) string {
+	switch kind {
+	case metricCount:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case metricRate:
+		return fmt.Sprintf("%.2f", v)
+	case metricRatio:
+		return fmt.Sprintf("%.5f％", v*100)
+	case metricDuration:
+		return time.Duration(v).Truncate(time.Microsecond).String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// parseMetricValue parses the value in the unit of metric kind; the duration
+// can be `8s` or `500ms`, the ratio can be `0.1%` or `0.001`.
+func parseMetricValue(kind metricKind, s string) (v float64, err error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case kind == metricDuration:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err != nil {
+			return
+		}
+		v = float64(d)
+	case strings.HasSuffix(s, "%"):
+		if v, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64); err != nil {
+			return
+		}
+		v = v / 100
+	default:
+		v, err = strconv.ParseFloat(s, 64)
+	}
+
+	return
+}
+
+// Metrics returns the numbers of result; requests, operations, tps,
+// expected_ops, real_ops, error_rate, error_rate.<error type>,
+// error_category.<category>, sebak_error.<code>, min, mean, max, stddev and percentiles like p99. The
+// metrics of creating accounts are setup_duration, provisioning_errors,
+// provisioning_p50, provisioning_p99 and provisioning_max. The separated
+// latencies are submit_p50, submit_p99, confirm_p50, confirm_p99,
+// polling_overhead_p50 and polling_overhead_p99. The delay of inclusion in
+// blocks are missed_next_block, inclusion_blocks_p50 and
+// inclusion_blocks_p99. The metrics of
+// accounts are fairness, Jain's fairness index of requests by source account,
+// and stopped_accounts.
+func (r *resultReport) Metrics() []resultMetric {
+	seconds := r.TotalElapsed.Seconds()
+	perSecond := func(v int) float64 {
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(v) / seconds
+	}
+	ratio := func(v int) float64 {
+		if r.Requests < 1 {
+			return 0
+		}
+		return float64(v) / float64(r.Requests)
+	}
+
+	// NOTE without the measured values, the latency and throughput are not 0,
+	// but no data
+	noRequests := r.Requests < 1 || seconds <= 0
+	noLatency := r.Histogram.Count() < 1
+	noProvisioning := r.Provisioning.Histogram.Count() < 1
+	noSubmit := r.Latency.Records < 1
+	noConfirmation := r.Latency.Confirmed < 1
+	noInclusion := r.Inclusion.Payments < 1
+
+	metrics := []resultMetric{
+		{Name: "requests", Kind: metricCount, Value: float64(r.Requests), HigherIsBetter: true},
+		{Name: "operations", Kind: metricCount, Value: float64(r.Operations), HigherIsBetter: true},
+		{Name: "tps", Kind: metricRate, Value: perSecond(r.Requests - r.Errors), HigherIsBetter: true, NoData: noRequests},
+		{Name: "expected_ops", Kind: metricRate, Value: perSecond(r.Operations), HigherIsBetter: true, NoData: noRequests},
+		{Name: "real_ops", Kind: metricRate, Value: perSecond(r.ConfirmedOperations), HigherIsBetter: true, NoData: noRequests},
+		{Name: "error_rate", Kind: metricRatio, Value: ratio(r.Errors), NoData: r.Requests < 1},
+		{Name: "min", Kind: metricDuration, Value: float64(r.Histogram.Min()), NoData: noLatency},
+		{Name: "mean", Kind: metricDuration, Value: r.Histogram.Mean(), NoData: noLatency},
+		{Name: "max", Kind: metricDuration, Value: float64(r.Histogram.Max()), NoData: noLatency},
+		{Name: "stddev", Kind: metricDuration, Value: r.Histogram.StdDev(), NoData: noLatency},
+	}
+
+	for _, p := range percentiles {
+		metrics = append(metrics, resultMetric{
+			Name:   fmt.Sprintf("p%v", p),
+			Kind:   metricDuration,
+			Value:  float64(r.Histogram.ValueAtPercentile(p)),
+			NoData: noLatency,
+		})
+	}
+
+	metrics = append(
+		metrics,
+		resultMetric{Name: "setup_duration", Kind: metricDuration, Value: float64(r.Provisioning.SetupDuration)},
+		resultMetric{Name: "provisioning_errors", Kind: metricCount, Value: float64(r.Provisioning.Errors)},
+		resultMetric{Name: "provisioning_p50", Kind: metricDuration, Value: float64(r.Provisioning.Histogram.ValueAtPercentile(50)), NoData: noProvisioning},
+		resultMetric{Name: "provisioning_p99", Kind: metricDuration, Value: float64(r.Provisioning.Histogram.ValueAtPercentile(99)), NoData: noProvisioning},
+		resultMetric{Name: "provisioning_max", Kind: metricDuration, Value: float64(r.Provisioning.Histogram.Max()), NoData: noProvisioning},
+		resultMetric{Name: "submit_p50", Kind: metricDuration, Value: float64(r.Latency.Submit.ValueAtPercentile(50)), NoData: noSubmit},
+		resultMetric{Name: "submit_p99", Kind: metricDuration, Value: float64(r.Latency.Submit.ValueAtPercentile(99)), NoData: noSubmit},
+		resultMetric{Name: "confirm_p50", Kind: metricDuration, Value: float64(r.Latency.Confirmation.ValueAtPercentile(50)), NoData: noConfirmation},
+		resultMetric{Name: "confirm_p99", Kind: metricDuration, Value: float64(r.Latency.Confirmation.ValueAtPercentile(99)), NoData: noConfirmation},
+		resultMetric{Name: "polling_overhead_p50", Kind: metricDuration, Value: float64(r.Latency.PollingOverhead.ValueAtPercentile(50)), NoData: noConfirmation},
+		resultMetric{Name: "polling_overhead_p99", Kind: metricDuration, Value: float64(r.Latency.PollingOverhead.ValueAtPercentile(99)), NoData: noConfirmation},
+		resultMetric{Name: "missed_next_block", Kind: metricRatio, Value: r.Inclusion.MissedRatio, NoData: noInclusion},
+		resultMetric{Name: "inclusion_blocks_p50", Kind: metricCount, Value: float64(r.Inclusion.P50), NoData: noInclusion},
+		resultMetric{Name: "inclusion_blocks_p99", Kind: metricCount, Value: float64(r.Inclusion.P99), NoData: noInclusion},
+		resultMetric{Name: "fairness", Kind: metricRatio, Value: r.Accounts.Fairness, HigherIsBetter: true, NoData: r.Accounts.Sources < 1},
+		resultMetric{Name: "stopped_accounts", Kind: metricCount, Value: float64(len(r.Accounts.Stopped))},
+	)
+
+	for errorType, count := range r.ErrorTypes {
+		metrics = append(metrics, resultMetric{
+			Name:  fmt.Sprintf("error_rate.%s", errorType),
+			Kind:  metricRatio,
+			Value: ratio(count),
+		})
+	}
+
+	for category, count := range r.ErrorCategories {
+		metrics = append(metrics, resultMetric{
+			Name:  fmt.Sprintf("error_category.%s", category),
+			Kind:  metricRatio,
+			Value: ratio(count),
+		})
+	}
+
+	for code, count := range r.SEBAKErrors {
+		metrics = append(metrics, resultMetric{
+			Name:  fmt.Sprintf("sebak_error.%d", code),
+			Kind:  metricCount,
+			Value: float64(count),
+		})
+	}
+
+	sortMetrics(metrics[len(metrics)-len(r.ErrorTypes)-len(r.ErrorCategories)-len(r.SEBAKErrors):])
+
+	return metrics
+}
+
+// sortMetrics sorts by name, but keeps the number order, `sebak_error.99`
+// comes before `sebak_error.100`.
+func sortMetrics(metrics []resultMetric) {
+	less := func(a, b string) bool {
+		i, j := strings.LastIndex(a, "."), strings.LastIndex(b, ".")
+		if i >= 0 && j >= 0 && a[:i] == b[:j] {
+			x, errX := strconv.Atoi(a[i+1:])
+			y, errY := strconv.Atoi(b[j+1:])
+			if errX == nil && errY == nil {
+				return x < y
+			}
+		}
+
+		return a < b
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return less(metrics[i].Name, metrics[j].Name)
+	})
+}
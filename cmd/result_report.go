@@ -0,0 +1,638 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apcera/termtables"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+type resultRow struct {
+	Key   string
+	Value interface{}
+	Text  string // NOTE if empty, Value will be shown
+}
+
+// Name returns the machine-readable key of row, `# requests` becomes
+// `requests` and `max elapsed time` becomes `max-elapsed-time`.
+func (r resultRow) Name() string {
+	s := strings.TrimSpace(strings.TrimPrefix(r.Key, "#"))
+	return strings.Replace(strings.ToLower(s), " ", "-", -1)
+}
+
+func (r resultRow) String() string {
+	if len(r.Text) > 0 {
+		return r.Text
+	}
+
+	switch r.Value.(type) {
+	case float64:
+		return fmt.Sprintf("%15.10f", r.Value)
+	default:
+		return fmt.Sprintf("%v", r.Value)
+	}
+}
+
+type resultSection struct {
+	Name string
+	Rows []resultRow
+}
+
+type errorCount struct {
+	Count int     `json:"count"`
+	Ratio float64 `json:"ratio"`
+}
+
+// explainedErrorCount is the errorCount with the explanation from error
+// catalogue.
+type explainedErrorCount struct {
+	errorCount
+	Name      string                `json:"name"`
+	Category  hotbody.ErrorCategory `json:"category"`
+	Retryable bool                  `json:"retryable"`
+}
+
+// resultReport is the analyzed result of <result log>, which can be rendered
+// into the several formats.
+type resultReport struct {
+	analysis.Summary
+	Sections   []resultSection
+	TimeSeries []timeSeries
+}
+
+func (r *resultReport) add(section string, key string, value interface{}, text ...string) {
+	row := resultRow{Key: key, Value: value}
+	if len(text) > 0 {
+		row.Text = text[0]
+	}
+
+	if len(r.Sections) < 1 || r.Sections[len(r.Sections)-1].Name != section {
+		r.Sections = append(r.Sections, resultSection{Name: section})
+	}
+
+	s := &r.Sections[len(r.Sections)-1]
+	s.Rows = append(s.Rows, row)
+}
+
+func formatAddress(s string) string {
+	if len(s) < 26 {
+		return s
+	}
+
+	return fmt.Sprintf("%s...%s", s[:13], s[len(s)-13:])
+}
+
+func newResultReport(rl analysis.Log) *resultReport {
+	report := &resultReport{
+		Summary: analysis.NewSummary(rl, analysis.Options{BucketWidth: bucketWidth}),
+	}
+	config, records, sebakErrors := report.Config, report.Records, report.SEBAKErrors
+	countError, errorTypes := report.Errors, report.ErrorTypes
+	started, lastTime := report.Started, report.Ended
+
+	// NOTE the window is shown even in brief, the statistics are not of the
+	// whole records.
+	if w := report.Window; w != nil {
+		phases := "all"
+		if len(w.Phases) > 0 {
+			phases = strings.Join(w.Phases, ", ")
+		}
+
+		report.add("window", "since", w.Since, FormatISO8601(w.Since))
+		report.add("window", "until", w.Until, FormatISO8601(w.Until))
+		report.add("window", "duration", w.Until.Sub(w.Since))
+		report.add("window", "phases", w.Phases, phases)
+		report.add("window", "# excluded records", w.Excluded)
+	}
+
+	if !flagBrief {
+		report.add("config", "testing time", config.Timeout)
+		report.add("config", "concurrent requests", config.T)
+		report.add("config", "initial account", config.InitAccount, formatAddress(config.InitAccount))
+		report.add("config", "request timeout", config.RequestTimeout)
+		report.add("config", "confirm duration", config.ConfirmDuration)
+		report.add("config", "operations", config.Operations)
+		if config.Seed != 0 {
+			report.add("config", "seed", config.Seed)
+		}
+		if config.Replay != nil {
+			report.add("config", "replay of", config.Replay.Source)
+			if len(config.Replay.Run) > 0 {
+				report.add("config", "replayed run id", config.Replay.Run)
+			}
+			report.add("config", "replay speed", config.Replay.Speed)
+		}
+		if len(rl.Sources) > 1 {
+			report.add("config", "# sources", len(rl.Sources))
+		}
+		if len(report.Run) > 0 {
+			report.add("config", "run id", report.Run)
+		}
+		report.add("config", "schema version", report.Version)
+		if report.MissingRecords > 0 {
+			report.add("config", "# missing records", report.MissingRecords)
+		}
+
+		report.add("network", "network id", config.Node.Policy.NetworkID)
+		report.add("network", "initial balance", config.Node.Policy.InitialBalance)
+		report.add("network", "block time", config.Node.Policy.BlockTime)
+		report.add("network", "base reserve", config.Node.Policy.BaseReserve)
+		report.add("network", "base fee", config.Node.Policy.BaseFee)
+
+		report.add("node", "endpoint", fmt.Sprintf("%v", config.Node.Node.Endpoint))
+		report.add("node", "address", config.Node.Node.Address, formatAddress(config.Node.Node.Address))
+		report.add("node", "state", fmt.Sprintf("%v", config.Node.Node.State))
+		report.add("node", "block height", config.Node.Block.Height)
+		report.add("node", "block hash", config.Node.Block.Hash, formatAddress(config.Node.Block.Hash))
+		report.add("node", "block totaltxs", config.Node.Block.TotalTxs)
+		report.add("node", "block totalops", config.Node.Block.TotalOps)
+	}
+
+	if !flagBrief {
+		report.add("time", "started", started, FormatISO8601(started))
+		report.add("time", "ended", lastTime, FormatISO8601(lastTime))
+		report.add("time", "total elapsed", lastTime.Sub(started))
+	}
+
+	if !flagBrief {
+		p := report.Provisioning
+		report.add("provisioning", "# transactions", p.Transactions)
+		report.add("provisioning", "# accounts", p.Accounts)
+		report.add(
+			"provisioning",
+			"failures",
+			errorCountValue(p.Errors, p.Transactions),
+			formatErrorCount(p.Errors, p.Transactions),
+		)
+		report.add("provisioning", "setup duration", p.SetupDuration)
+		report.add("provisioning", "min elapsed time", time.Duration(p.Histogram.Min()))
+		report.add("provisioning", "max elapsed time", time.Duration(p.Histogram.Max()))
+		report.add("provisioning", "mean elapsed time", time.Duration(p.Histogram.Mean()))
+		for _, i := range []float64{50, 90, 99} {
+			report.add(
+				"provisioning",
+				fmt.Sprintf("p%v elapsed time", i),
+				time.Duration(p.Histogram.ValueAtPercentile(i)),
+			)
+		}
+		report.add("provisioning", "distribution", p.Distribution, fmt.Sprintf("bucket: %v", p.BucketWidth))
+
+		report.addAccounts(report.Accounts)
+	}
+
+	{
+		report.add("result", "# requests", len(records))
+		report.add("result", "# operations", report.Operations)
+		report.add(
+			"result",
+			"error rates",
+			float64(countError)/float64(len(records)),
+			fmt.Sprintf(
+				"%2.5f％ (%d/%d)",
+				float64(countError)/float64(len(records))*100,
+				countError,
+				len(records),
+			),
+		)
+		report.add("result", "min elapsed time", time.Duration(report.Histogram.Min()))
+		report.add("result", "max elapsed time", time.Duration(report.Histogram.Max()))
+		report.add("result", "mean elapsed time", time.Duration(report.Histogram.Mean()))
+		report.add("result", "stddev elapsed time", time.Duration(report.Histogram.StdDev()))
+		for _, p := range percentiles {
+			report.add(
+				"result",
+				fmt.Sprintf("p%v elapsed time", p),
+				time.Duration(report.Histogram.ValueAtPercentile(p)),
+			)
+		}
+
+		report.add("result", "distribution", report.Distribution, fmt.Sprintf("bucket: %v", report.BucketWidth))
+
+		report.add("result", "expected OPS", int(report.ExpectedOPS))
+		report.add("result", "real OPS", int(report.RealOPS))
+	}
+
+	if !flagBrief {
+		report.addLatencyBreakdown(report.Latency)
+		report.addInclusion(report.Inclusion)
+		report.addAggregators()
+	}
+
+	for _, source := range rl.Sources {
+		s := newSourceSummary(source)
+		report.add("source", s.File, s, s.String())
+	}
+
+	{
+		if countError < 1 {
+			report.add("error", "no error", nil, " ")
+		} else {
+			var keys []string
+			for errorType := range errorTypes {
+				keys = append(keys, string(errorType))
+			}
+			sortByCategory(keys, func(k string) hotbody.ErrorCategory {
+				return report.ErrorInfos[hotbody.RecordErrorType(k)].Category
+			})
+
+			for _, k := range keys {
+				count := errorTypes[hotbody.RecordErrorType(k)]
+				info := report.ErrorInfos[hotbody.RecordErrorType(k)]
+				report.add(
+					"error",
+					k,
+					explainedErrorCountValue(count, countError, info),
+					formatExplainedErrorCount(count, countError, info),
+				)
+			}
+
+			for _, category := range hotbody.ErrorCategories {
+				count, found := report.ErrorCategories[category]
+				if !found {
+					continue
+				}
+				report.add(
+					"error-category",
+					string(category),
+					errorCountValue(count, countError),
+					formatErrorCount(count, countError),
+				)
+			}
+			report.add(
+				"error-category",
+				"retryable",
+				errorCountValue(report.RetryableErrors, countError),
+				formatErrorCount(report.RetryableErrors, countError),
+			)
+			report.add(
+				"error-category",
+				"not retryable",
+				errorCountValue(countError-report.RetryableErrors, countError),
+				formatErrorCount(countError-report.RetryableErrors, countError),
+			)
+		}
+	}
+
+	{
+		if len(sebakErrors) < 1 {
+			report.add("sebak-error", "no error", nil, " ")
+		} else {
+			var countSEBAKError int
+			var codes []int
+			for code, errorCount := range sebakErrors {
+				countSEBAKError += errorCount
+				codes = append(codes, code)
+			}
+			sort.Ints(codes)
+			sort.SliceStable(codes, func(i, j int) bool {
+				return categoryOrder(report.SEBAKErrorInfos[codes[i]].Category) <
+					categoryOrder(report.SEBAKErrorInfos[codes[j]].Category)
+			})
+
+			for _, code := range codes {
+				info := report.SEBAKErrorInfos[code]
+				report.add(
+					"sebak-error",
+					fmt.Sprintf("sebak-error-%d", code),
+					explainedErrorCountValue(sebakErrors[code], countSEBAKError, info),
+					formatExplainedErrorCount(sebakErrors[code], countSEBAKError, info),
+				)
+			}
+		}
+	}
+
+	for _, window := range windows {
+		report.TimeSeries = append(report.TimeSeries, newTimeSeries(window, started, records))
+	}
+
+	return report
+}
+
+func errorCountValue(count, total int) errorCount {
+	return errorCount{Count: count, Ratio: analysis.ErrorRatio(count, total)}
+}
+
+func explainedErrorCountValue(count, total int, info hotbody.ErrorInfo) explainedErrorCount {
+	return explainedErrorCount{
+		errorCount: errorCountValue(count, total),
+		Name:       info.Name,
+		Category:   info.Category,
+		Retryable:  info.Retryable,
+	}
+}
+
+// formatExplainedErrorCount shows the category, name and whether retryable
+// after the count, like `12 | 0.10000％ | consensus | transaction-not-found,
+// retryable`.
+func formatExplainedErrorCount(count, total int, info hotbody.ErrorInfo) string {
+	retryable := "not retryable"
+	if info.Retryable {
+		retryable = "retryable"
+	}
+
+	return fmt.Sprintf(
+		"%s | %s | %s, %s",
+		formatErrorCount(count, total),
+		info.Category,
+		info.Name,
+		retryable,
+	)
+}
+
+func categoryOrder(category hotbody.ErrorCategory) int {
+	for i, c := range hotbody.ErrorCategories {
+		if c == category {
+			return i
+		}
+	}
+
+	return len(hotbody.ErrorCategories)
+}
+
+// sortByCategory sorts the error types by the order of
+// hotbody.ErrorCategories and then by name.
+func sortByCategory(keys []string, categoryOf func(string) hotbody.ErrorCategory) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := categoryOrder(categoryOf(keys[i])), categoryOrder(categoryOf(keys[j]))
+		if a != b {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+func formatErrorCount(count, total int) string {
+	return fmt.Sprintf(
+		"%d | % 10s",
+		count,
+		fmt.Sprintf("%.5f％", analysis.ErrorRatio(count, total)*100),
+	)
+}
+
+func (r *resultReport) Render(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return r.renderJSON(w)
+	case "csv":
+		return r.renderCSV(w)
+	case "markdown":
+		return r.renderMarkdown(w)
+	case "html":
+		return r.renderHTML(w)
+	default:
+		return r.renderTerminal(w)
+	}
+}
+
+func (r *resultReport) renderTerminal(w io.Writer) error {
+	alignKey := func(s string) string {
+		return fmt.Sprintf("% 20s", s)
+	}
+
+	alignValue := func(s string) string {
+		return fmt.Sprintf("%30s", s)
+	}
+
+	alignHead := func(s string) string {
+		return fmt.Sprintf("* %-10s", s)
+	}
+
+	table := termtables.CreateTable()
+	for i, section := range r.Sections {
+		if i > 0 {
+			table.AddSeparator()
+		}
+
+		for j, row := range section.Rows {
+			head := ""
+			if j == 0 {
+				head = alignHead(section.Name)
+			}
+
+			if row.Value == nil {
+				table.AddRow(head, alignKey(row.Key), "")
+				continue
+			}
+			table.AddRow(head, alignKey(row.Key), alignValue(row.String()))
+
+			if distribution, ok := row.Value.([]analysis.DistributionBucket); ok {
+				for _, b := range distribution {
+					table.AddRow(
+						"",
+						"",
+						alignValue(fmt.Sprintf(
+							"%s: %9.5f％ / %5d",
+							fmt.Sprintf("%v-%v", b.Low, b.High),
+							b.Ratio*100,
+							b.Count,
+						)),
+					)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(w, table.Render())
+
+	for _, ts := range r.TimeSeries {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, ts.Render())
+	}
+
+	return nil
+}
+
+// Map returns the sections as map, which has the structured values.
+func (r *resultReport) Map() map[string]interface{} {
+	m := map[string]interface{}{}
+	for _, section := range r.Sections {
+		rows := map[string]interface{}{}
+		for _, row := range section.Rows {
+			if row.Value == nil {
+				continue
+			}
+			rows[uniqueKey(rows, row.Name())] = row.Value
+		}
+		m[uniqueKey(m, section.Name)] = rows
+	}
+
+	if len(r.TimeSeries) > 0 {
+		m["time-series"] = r.TimeSeries
+	}
+
+	return m
+}
+
+// uniqueKey returns the key, which is not in m; the duplicated key has the
+// number suffix, the second `error` becomes `error-2`, so the earlier one is
+// not overwritten.
+func uniqueKey(m map[string]interface{}, key string) string {
+	if _, found := m[key]; !found {
+		return key
+	}
+
+	for i := 2; ; i++ {
+		k := fmt.Sprintf("%s-%d", key, i)
+		if _, found := m[k]; !found {
+			return k
+		}
+	}
+}
+
+func (r *resultReport) renderJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(r.Map(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
+}
+
+// flattenValue flattens the structured value into the pairs of dotted key and
+// value thru JSON.
+func flattenValue(prefix string, v interface{}) (pairs [][2]string) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return [][2]string{{prefix, fmt.Sprintf("%v", v)}}
+	}
+
+	var i interface{}
+	json.Unmarshal(b, &i)
+
+	var flatten func(string, interface{})
+	flatten = func(key string, i interface{}) {
+		switch t := i.(type) {
+		case map[string]interface{}:
+			var keys []string
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				flatten(key+"."+k, t[k])
+			}
+		case []interface{}:
+			for n, e := range t {
+				flatten(fmt.Sprintf("%s.%d", key, n), e)
+			}
+		case nil:
+			pairs = append(pairs, [2]string{key, ""})
+		case float64:
+			pairs = append(pairs, [2]string{key, strconv.FormatFloat(t, 'f', -1, 64)})
+		default:
+			pairs = append(pairs, [2]string{key, fmt.Sprintf("%v", t)})
+		}
+	}
+	flatten(prefix, i)
+
+	return
+}
+
+func (r *resultReport) renderCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	if err := c.Write([]string{"section", "key", "value"}); err != nil {
+		return err
+	}
+
+	for _, section := range r.Sections {
+		for _, row := range section.Rows {
+			if row.Value == nil {
+				continue
+			}
+			for _, pair := range flattenValue(row.Name(), row.Value) {
+				if err := c.Write([]string{section.Name, pair[0], pair[1]}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, ts := range r.TimeSeries {
+		for _, pair := range flattenValue(ts.Window.String(), ts.Points) {
+			if err := c.Write([]string{"time-series", pair[0], pair[1]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
+
+func (r *resultReport) renderMarkdown(w io.Writer) error {
+	escape := func(s string) string {
+		return strings.Replace(strings.TrimSpace(s), "|", "\\|", -1)
+	}
+
+	fmt.Fprintln(w, "# sebak-hot-body result")
+	for _, section := range r.Sections {
+		fmt.Fprintf(w, "\n## %s\n\n", section.Name)
+		fmt.Fprintln(w, "| key | value |")
+		fmt.Fprintln(w, "| --- | ---: |")
+		for _, row := range section.Rows {
+			fmt.Fprintf(w, "| %s | %s |\n", escape(row.Key), escape(row.String()))
+
+			if distribution, ok := row.Value.([]analysis.DistributionBucket); ok {
+				for _, b := range distribution {
+					fmt.Fprintf(
+						w,
+						"| %v-%v | %.5f％ / %d |\n",
+						b.Low,
+						b.High,
+						b.Ratio*100,
+						b.Count,
+					)
+				}
+			}
+		}
+	}
+
+	for _, ts := range r.TimeSeries {
+		fmt.Fprintf(w, "\n## time series, window: %v\n\n", ts.Window)
+		fmt.Fprintln(w, "| offset | submitted | confirmed | operations | errors | error rate | p50 | p90 | p99 |")
+		fmt.Fprintln(w, "| ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: |")
+		for _, p := range ts.Points {
+			fmt.Fprintf(
+				w,
+				"| %v | %d | %d | %d | %d | %.5f％ | %v | %v | %v |\n",
+				p.Offset,
+				p.Submitted,
+				p.Confirmed,
+				p.Operations,
+				p.Errors,
+				p.ErrorRate*100,
+				p.P50.Truncate(time.Millisecond),
+				p.P90.Truncate(time.Millisecond),
+				p.P99.Truncate(time.Millisecond),
+			)
+		}
+	}
+
+	return nil
+}
+
+// addAggregators adds the summaries of the registered aggregators as
+// sections.
+func (r *resultReport) addAggregators() {
+	for _, a := range r.Aggregators {
+		for _, row := range a.Summary() {
+			if len(row.Text) > 0 {
+				r.add(a.Name(), row.Key, row.Value, row.Text)
+			} else {
+				r.add(a.Name(), row.Key, row.Value)
+			}
+		}
+	}
+}
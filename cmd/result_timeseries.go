@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apcera/termtables"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+type timeSeriesPoint struct {
+	Start      time.Time     `json:"start"`
+	Offset     time.Duration `json:"offset"`
+	Submitted  int           `json:"submitted"`
+	Confirmed  int           `json:"confirmed"`
+	Operations int           `json:"operations"`
+	Errors     int           `json:"errors"`
+	ErrorRate  float64       `json:"error-rate"`
+	TPS        float64       `json:"tps"`
+	OPS        float64       `json:"ops"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P99        time.Duration `json:"p99"`
+}
+
+type timeSeries struct {
+	Window time.Duration     `json:"window"`
+	Points []timeSeriesPoint `json:"points"`
+}
+
+// newTimeSeries splits the payment records into the windows from started.
+// The transaction is submitted at `time - elapsed` and it is confirmed at
+// `time`.
+func newTimeSeries(window time.Duration, started time.Time, records []hotbody.Record) timeSeries {
+	ts := timeSeries{Window: window}
+	if len(records) < 1 {
+		return ts
+	}
+
+	var last time.Time
+	for _, r := range records {
+		if r.GetTime().After(last) {
+			last = r.GetTime()
+		}
+	}
+
+	n := int(last.Sub(started)/window) + 1
+	if n < 1 {
+		n = 1
+	}
+
+	histograms := make([]*hotbody.Histogram, n)
+	ts.Points = make([]timeSeriesPoint, n)
+	for i := range ts.Points {
+		ts.Points[i].Start = started.Add(window * time.Duration(i))
+		ts.Points[i].Offset = window * time.Duration(i)
+		histograms[i] = hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+	}
+
+	index := func(t time.Time) int {
+		i := int(t.Sub(started) / window)
+		if i < 0 {
+			return 0
+		} else if i >= n {
+			return n - 1
+		}
+		return i
+	}
+
+	for _, r := range records {
+		elapsed := time.Duration(r.GetElapsed())
+		ts.Points[index(r.GetTime().Add(-elapsed))].Submitted++
+
+		i := index(r.GetTime())
+		if r.GetError() != nil {
+			ts.Points[i].Errors++
+			continue
+		}
+
+		// NOTE the latency of failed payment, like timeout, is not recorded
+		histograms[i].Record(int64(elapsed))
+		ts.Points[i].Confirmed++
+		if payment, ok := r.(hotbody.RecordPayment); ok {
+			ts.Points[i].Operations += int(payment.Count)
+		}
+	}
+
+	for i := range ts.Points {
+		p := &ts.Points[i]
+		if completed := p.Confirmed + p.Errors; completed > 0 {
+			p.ErrorRate = float64(p.Errors) / float64(completed)
+		}
+		p.TPS = float64(p.Confirmed) / window.Seconds()
+		p.OPS = float64(p.Operations) / window.Seconds()
+		p.P50 = time.Duration(histograms[i].ValueAtPercentile(50))
+		p.P90 = time.Duration(histograms[i].ValueAtPercentile(90))
+		p.P99 = time.Duration(histograms[i].ValueAtPercentile(99))
+	}
+
+	return ts
+}
+
+func sparkline(values []float64) string {
+	var max float64
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+
+	var s []rune
+	for _, v := range values {
+		if max <= 0 {
+			s = append(s, sparklineBlocks[0])
+			continue
+		}
+		s = append(s, sparklineBlocks[int(v/max*float64(len(sparklineBlocks)-1)+0.5)])
+	}
+
+	return string(s)
+}
+
+func (ts timeSeries) values(f func(timeSeriesPoint) float64) []float64 {
+	var values []float64
+	for _, p := range ts.Points {
+		values = append(values, f(p))
+	}
+
+	return values
+}
+
+func (ts timeSeries) Render() string {
+	table := termtables.CreateTable()
+	table.AddTitle(fmt.Sprintf("time series, window: %v", ts.Window))
+	table.AddHeaders("offset", "submitted", "confirmed", "operations", "errors", "error rate", "p50", "p90", "p99")
+
+	for _, p := range ts.Points {
+		table.AddRow(
+			p.Offset,
+			p.Submitted,
+			p.Confirmed,
+			p.Operations,
+			p.Errors,
+			fmt.Sprintf("%.5f％", p.ErrorRate*100),
+			p.P50.Truncate(time.Millisecond),
+			p.P90.Truncate(time.Millisecond),
+			p.P99.Truncate(time.Millisecond),
+		)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%-12s %s", "TPS", sparkline(ts.values(func(p timeSeriesPoint) float64 { return p.TPS }))),
+		fmt.Sprintf("%-12s %s", "error rate", sparkline(ts.values(func(p timeSeriesPoint) float64 { return p.ErrorRate }))),
+		fmt.Sprintf("%-12s %s", "p99", sparkline(ts.values(func(p timeSeriesPoint) float64 { return float64(p.P99) }))),
+	}
+
+	return table.Render() + strings.Join(lines, "\n") + "\n"
+}
+
+var timeSeriesCSVHeaders = []string{
+	"window",
+	"start",
+	"offset-seconds",
+	"submitted",
+	"confirmed",
+	"operations",
+	"errors",
+	"error-rate",
+	"tps",
+	"ops",
+	"p50-ns",
+	"p90-ns",
+	"p99-ns",
+}
+
+func writeTimeSeriesCSV(w io.Writer, series []timeSeries) error {
+	c := csv.NewWriter(w)
+	if err := c.Write(timeSeriesCSVHeaders); err != nil {
+		return err
+	}
+
+	for _, ts := range series {
+		for _, p := range ts.Points {
+			err := c.Write([]string{
+				ts.Window.String(),
+				p.Start.Format(time.RFC3339Nano),
+				strconv.FormatFloat(p.Offset.Seconds(), 'f', -1, 64),
+				strconv.Itoa(p.Submitted),
+				strconv.Itoa(p.Confirmed),
+				strconv.Itoa(p.Operations),
+				strconv.Itoa(p.Errors),
+				strconv.FormatFloat(p.ErrorRate, 'f', -1, 64),
+				strconv.FormatFloat(p.TPS, 'f', -1, 64),
+				strconv.FormatFloat(p.OPS, 'f', -1, 64),
+				strconv.FormatInt(int64(p.P50), 10),
+				strconv.FormatInt(int64(p.P90), 10),
+				strconv.FormatInt(int64(p.P99), 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
+
+func writeTimeSeriesJSON(w io.Writer, series []timeSeries) error {
+	b, err := json.MarshalIndent(series, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
+}
+
+func parseWindows(s string) (windows []time.Duration, err error) {
+	for _, i := range strings.Split(s, ",") {
+		i = strings.TrimSpace(i)
+		if len(i) < 1 {
+			continue
+		}
+
+		var d time.Duration
+		if d, err = time.ParseDuration(i); err != nil {
+			return
+		} else if d <= 0 {
+			err = fmt.Errorf("window must be bigger than 0: %v", i)
+			return
+		}
+		windows = append(windows, d)
+	}
+
+	return
+}
@@ -11,6 +11,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+	"github.com/spikeekips/sebak-hot-body/hotbody/runner"
+)
+
+const (
+	exitCodeRegression int = 2 // NOTE `compare` found the regression over the thresholds
+	exitCodeAssertion  int = 3 // NOTE some of `--assert` failed
 )
 
 func printFlagsError(cmd *cobra.Command, flagName string, err error) {
@@ -33,6 +40,24 @@ func printError(cmd *cobra.Command, err error) {
 	os.Exit(1)
 }
 
+// parseErrorCatalogueFlag adds the SEBAK errors of `--error-catalogue` to the
+// error catalogue.
+func parseErrorCatalogueFlag(cmd *cobra.Command) {
+	if len(flagErrorCatalogue) < 1 {
+		return
+	}
+
+	f, err := os.Open(flagErrorCatalogue)
+	if err != nil {
+		printFlagsError(cmd, "--error-catalogue", err)
+	}
+	defer f.Close()
+
+	if err = hotbody.LoadErrorCatalogue(f); err != nil {
+		printFlagsError(cmd, "--error-catalogue", err)
+	}
+}
+
 func FormatISO8601(t time.Time) string {
 	return t.Format("2006-01-02T15:04:05.000000000")
 }
@@ -46,7 +71,7 @@ func setLogging() {
 	var logFormatter logging.Format
 	switch flagLogFormat {
 	case "terminal":
-		if isatty.IsTerminal(os.Stdout.Fd()) && len(flagLog) < 1 {
+		if isatty.IsTerminal(os.Stderr.Fd()) && len(flagLog) < 1 {
 			logFormatter = logging.TerminalFormat()
 		} else {
 			logFormatter = logging.LogfmtFormat()
@@ -57,13 +82,21 @@ func setLogging() {
 		printFlagsError(goCmd, "--log-format", fmt.Errorf("'%s'", flagLogFormat))
 	}
 
-	logHandler := logging.StreamHandler(os.Stdout, logFormatter)
+	// NOTE the log is written to stderr, so it is not mixed with the output
+	// of commands, like `result --format json`.
+	logHandler := logging.StreamHandler(os.Stderr, logFormatter)
 	if len(flagLog) > 0 {
 		if logHandler, err = logging.FileHandler(flagLog, logFormatter); err != nil {
 			printFlagsError(goCmd, "--log", err)
 		}
+	} else if flagDashboard {
+		// NOTE dashboard uses the whole terminal, the log is shown only when
+		// --log is given.
+		logHandler = logging.DiscardHandler()
 	}
 
 	log.SetHandler(logging.LvlFilterHandler(logLevel, logging.CallerFileHandler(logHandler)))
 	hotbody.SetLogging(logLevel, logHandler)
+	analysis.SetLogging(logLevel, logHandler)
+	runner.SetLogging(logLevel, logHandler)
 }
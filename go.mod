@@ -8,8 +8,11 @@ require (
 	github.com/mattn/go-isatty v0.0.3
 	github.com/mattn/go-runewidth v0.0.3 // indirect
 	github.com/spf13/cobra v0.0.3
+	github.com/spf13/pflag v1.0.1
 	github.com/stellar/go v0.0.0-20181008142645-92db8e1f6fa5
 	github.com/stellar/go-xdr v0.0.0-20180917104419-0bc96f33a18e // indirect
 	golang.org/x/net v0.0.0-20181017193950-04a2e542c03f
 	golang.org/x/sys v0.0.0-20180905080454-ebe1bf3edb33 // indirect
+	gonum.org/v1/plot v0.12.0
+	gopkg.in/yaml.v2 v2.2.1
 )
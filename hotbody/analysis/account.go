@@ -0,0 +1,200 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// AccountSummary is the statistics of the payments from the source account.
+type AccountSummary struct {
+	Address    string        `json:"address"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Targeted   int           `json:"targeted"` // NOTE number of payments, which the account received
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	Last       time.Time     `json:"last"` // NOTE time of last payment
+	Stopped    bool          `json:"stopped"`
+	StopReason string        `json:"stop-reason"`
+}
+
+func (s AccountSummary) String() string {
+	t := fmt.Sprintf(
+		"%d requests | %d errors | %d targeted | p50 %v | p99 %v | max %v",
+		s.Requests,
+		s.Errors,
+		s.Targeted,
+		s.P50.Truncate(time.Millisecond),
+		s.P99.Truncate(time.Millisecond),
+		s.Max.Truncate(time.Millisecond),
+	)
+	if s.Stopped {
+		t += fmt.Sprintf(" | stopped: %s", s.StopReason)
+	}
+
+	return t
+}
+
+// AccountReport is the account-level result; how evenly the accounts are
+// used as the source and target of payments. The requests statistics and
+// fairness are of the source accounts only, the created accounts, which were
+// never dispatched as source, like the reserve accounts, are in NeverSource.
+type AccountReport struct {
+	Accounts     []AccountSummary `json:"accounts"` // NOTE ordered by requests
+	Sources      int              `json:"sources"`
+	NeverSource  []string         `json:"never-source"`
+	NeverTarget  []string         `json:"never-target"`
+	Stopped      []string         `json:"stopped"`
+	MinRequests  int              `json:"min-requests"`
+	MaxRequests  int              `json:"max-requests"`
+	MeanRequests float64          `json:"mean-requests"`
+	Fairness     float64          `json:"fairness"` // NOTE Jain's fairness index of requests
+}
+
+// JainFairness returns the Jain's fairness index, (Σx)² / (n * Σx²); 1 means
+// all the values are same and 1/n means only one has all.
+func JainFairness(values []int) float64 {
+	var sum, squares float64
+	for _, v := range values {
+		sum += float64(v)
+		squares += float64(v) * float64(v)
+	}
+	if squares == 0 {
+		return 0
+	}
+
+	return sum * sum / (float64(len(values)) * squares)
+}
+
+// durationAtPercentile returns the value at percentile of the sorted
+// durations by nearest rank.
+func durationAtPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) < 1 {
+		return 0
+	}
+
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// NewAccountReport analyzes the payments by the source and target accounts;
+// the created accounts, which are never used, are also counted.
+func NewAccountReport(l Log) (a AccountReport) {
+	summaries := map[string]*AccountSummary{}
+	var addresses []string
+	get := func(address string) *AccountSummary {
+		s, found := summaries[address]
+		if !found {
+			s = &AccountSummary{Address: address}
+			summaries[address] = s
+			addresses = append(addresses, address)
+		}
+		return s
+	}
+
+	for _, address := range l.Accounts {
+		get(address)
+	}
+
+	elapsed := map[string][]time.Duration{}
+	for _, r := range l.Records {
+		p, ok := r.(hotbody.RecordPayment)
+		if !ok {
+			continue
+		}
+
+		for _, target := range p.Addresses {
+			get(target).Targeted++
+		}
+
+		if len(p.Source) < 1 {
+			continue
+		}
+		s := get(p.Source)
+		s.Requests++
+		s.Last = p.GetTime()
+		if p.GetError() != nil {
+			s.Errors++
+		}
+		elapsed[p.Source] = append(elapsed[p.Source], time.Duration(p.GetElapsed()))
+	}
+
+	for _, r := range l.StoppedAccounts {
+		stopped, ok := r.(hotbody.RecordAccountStopped)
+		if !ok {
+			continue
+		}
+
+		s := get(stopped.Address)
+		s.Stopped = true
+		s.StopReason = stopped.Reason
+	}
+
+	if len(addresses) < 1 {
+		return
+	}
+
+	var requests []int // NOTE requests of source accounts
+	for _, address := range addresses {
+		s := summaries[address]
+
+		d := elapsed[address]
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+		s.P50 = durationAtPercentile(d, 50)
+		s.P99 = durationAtPercentile(d, 99)
+		if len(d) > 0 {
+			s.Max = d[len(d)-1]
+		}
+
+		// NOTE the account stopped before the first payment was also
+		// dispatched as source.
+		if s.Requests < 1 && !s.Stopped {
+			a.NeverSource = append(a.NeverSource, address)
+		} else {
+			requests = append(requests, s.Requests)
+		}
+		if s.Targeted < 1 {
+			a.NeverTarget = append(a.NeverTarget, address)
+		}
+		if s.Stopped {
+			a.Stopped = append(a.Stopped, address)
+		}
+
+		a.Accounts = append(a.Accounts, *s)
+	}
+
+	sort.SliceStable(a.Accounts, func(i, j int) bool {
+		return a.Accounts[i].Requests > a.Accounts[j].Requests
+	})
+
+	a.Sources = len(requests)
+	if a.Sources < 1 {
+		return
+	}
+
+	var sum int
+	a.MinRequests = requests[0]
+	for _, r := range requests {
+		sum += r
+		if r < a.MinRequests {
+			a.MinRequests = r
+		}
+		if r > a.MaxRequests {
+			a.MaxRequests = r
+		}
+	}
+	a.MeanRequests = float64(sum) / float64(len(requests))
+	a.Fairness = JainFairness(requests)
+
+	return
+}
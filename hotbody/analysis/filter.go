@@ -0,0 +1,246 @@
+package analysis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// phases of <result log> by the time of record; `provisioning` is before
+// `started`, `running` is until the deadline, `started` + timeout, and `drain`
+// is after the deadline until `ended`, when the existing requests are
+// finished.
+const (
+	PhaseProvisioning string = "provisioning"
+	PhaseRunning      string = "running"
+	PhaseDrain        string = "drain"
+)
+
+var Phases = []string{PhaseProvisioning, PhaseRunning, PhaseDrain}
+
+// FilterTime is the time of `Since` and `Until` of Filter; the time or the
+// duration from `started`.
+type FilterTime struct {
+	Time   time.Time
+	Offset time.Duration
+	IsSet  bool
+}
+
+// Resolve returns the time; the duration is added to started.
+func (t FilterTime) Resolve(started time.Time) time.Time {
+	if !t.Time.IsZero() {
+		return t.Time
+	}
+
+	return started.Add(t.Offset)
+}
+
+// Filter restricts the records, which feed the statistics.
+type Filter struct {
+	Since        FilterTime
+	Until        FilterTime
+	SkipWarmup   time.Duration
+	SkipCooldown time.Duration
+	Phases       []string
+}
+
+func (f Filter) IsEmpty() bool {
+	return !f.Since.IsSet && !f.Until.IsSet && f.SkipWarmup == 0 && f.SkipCooldown == 0 && len(f.Phases) < 1
+}
+
+// Window is the effective window of the filtered <result log>.
+type Window struct {
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	Phases   []string  `json:"phases"`
+	Excluded int       `json:"excluded"` // NOTE number of excluded payment records
+}
+
+// Deadline returns the time, when `go` stopped sending new requests;
+// `started` + timeout with the `extend` and `end` of control API.
+func (l Log) Deadline(started time.Time) time.Time {
+	deadline := started.Add(l.Config.Timeout)
+	for _, r := range l.Controls {
+		c, ok := r.(hotbody.RecordControl)
+		if !ok {
+			continue
+		}
+
+		switch c.Action {
+		case "extend":
+			if d, ok := c.Value.(float64); ok {
+				deadline = deadline.Add(time.Duration(d))
+			}
+		case "end":
+			deadline = c.GetTime()
+		}
+	}
+
+	return deadline
+}
+
+// FilterLog filters the records of <result log> by the time of record. The
+// window is from `Since` or `started` + `SkipWarmup` to `Until` or the
+// deadline - `SkipCooldown`; the later since and the earlier until are used.
+func FilterLog(l Log, f Filter) (filtered Log, err error) {
+	if f.IsEmpty() {
+		return l, nil
+	}
+
+	started := l.Begin()
+	deadline := l.Deadline(started)
+
+	window := &Window{Phases: f.Phases}
+	if f.SkipWarmup > 0 {
+		window.Since = started.Add(f.SkipWarmup)
+	}
+	if f.Since.IsSet {
+		if since := f.Since.Resolve(started); since.After(window.Since) {
+			window.Since = since
+		}
+	}
+	if f.SkipCooldown > 0 {
+		window.Until = deadline.Add(-f.SkipCooldown)
+	}
+	if f.Until.IsSet {
+		if until := f.Until.Resolve(started); window.Until.IsZero() || until.Before(window.Until) {
+			window.Until = until
+		}
+	}
+
+	if !window.Since.IsZero() && !window.Until.IsZero() && !window.Since.Before(window.Until) {
+		err = fmt.Errorf(
+			"empty window; since %s, until %s",
+			hotbody.FormatRecordTime(window.Since),
+			hotbody.FormatRecordTime(window.Until),
+		)
+		return
+	}
+
+	phaseOf := func(t time.Time) string {
+		switch {
+		case !l.Started.IsZero() && t.Before(l.Started):
+			return PhaseProvisioning
+		case t.Before(deadline):
+			return PhaseRunning
+		default:
+			return PhaseDrain
+		}
+	}
+
+	in := func(r hotbody.Record) bool {
+		t := r.GetTime()
+		if !window.Since.IsZero() && t.Before(window.Since) {
+			return false
+		}
+		if !window.Until.IsZero() && !t.Before(window.Until) {
+			return false
+		}
+		if len(f.Phases) < 1 {
+			return true
+		}
+
+		phase := phaseOf(t)
+		for _, p := range f.Phases {
+			if p == phase {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	filtered = l
+	filtered.Records = nil
+	filtered.CreateAccounts = nil
+	filtered.SEBAKErrorRecords = nil
+	filtered.SEBAKErrors = map[int]int{}
+	filtered.Controls = nil
+	filtered.StoppedAccounts = nil
+	filtered.Others = nil
+
+	for _, r := range l.Records {
+		if in(r) {
+			filtered.Records = append(filtered.Records, r)
+		}
+	}
+	for _, r := range l.CreateAccounts {
+		if in(r) {
+			filtered.CreateAccounts = append(filtered.CreateAccounts, r)
+		}
+	}
+	// NOTE the deadline is already resolved by the all controls, so the
+	// controls out of window are not needed
+	for _, r := range l.Controls {
+		if in(r) {
+			filtered.Controls = append(filtered.Controls, r)
+		}
+	}
+	for _, r := range l.StoppedAccounts {
+		if in(r) {
+			filtered.StoppedAccounts = append(filtered.StoppedAccounts, r)
+		}
+	}
+	for _, r := range l.Others {
+		if in(r) {
+			filtered.Others = append(filtered.Others, r)
+		}
+	}
+	for _, r := range l.SEBAKErrorRecords {
+		if !in(r) {
+			continue
+		}
+		filtered.SEBAKErrorRecords = append(filtered.SEBAKErrorRecords, r)
+		if code, ok := SEBAKErrorCode(r); ok {
+			filtered.SEBAKErrors[code]++
+		}
+	}
+
+	window.Excluded = len(l.Records) - len(filtered.Records)
+	// NOTE the window is narrowed to the span of the phases
+	if len(f.Phases) > 0 {
+		var since, until time.Time
+		for _, p := range f.Phases {
+			var s, u time.Time
+			switch p {
+			case PhaseProvisioning:
+				s, u = l.Created, l.Started
+			case PhaseRunning:
+				s, u = started, deadline
+			case PhaseDrain:
+				s, u = deadline, l.Ended
+			}
+			if since.IsZero() || s.Before(since) {
+				since = s
+			}
+			if u.IsZero() || until.IsZero() || u.After(until) {
+				until = u
+			}
+		}
+		if since.After(window.Since) {
+			window.Since = since
+		}
+		if !until.IsZero() && (window.Until.IsZero() || until.Before(window.Until)) {
+			window.Until = until
+		}
+	}
+
+	if window.Since.IsZero() {
+		window.Since = started
+	}
+	if window.Until.IsZero() && len(filtered.Records) > 0 {
+		window.Until = filtered.Records[len(filtered.Records)-1].GetTime()
+	}
+	// NOTE the window after `ended` does not have any record
+	if !l.Ended.IsZero() && window.Until.After(l.Ended) {
+		window.Until = l.Ended
+	}
+	filtered.Window = window
+
+	if len(filtered.Records) < 1 {
+		err = ErrNoRecords
+	}
+
+	return
+}
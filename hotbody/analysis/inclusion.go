@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// MaxInclusionBuckets limits the rows of blocks until inclusion; the more
+// blocks are counted in the last row.
+const MaxInclusionBuckets int64 = 10
+
+// InclusionBucket is the number of payments, which were stored in block after
+// the blocks; the overflow bucket has the payments of the blocks or more.
+type InclusionBucket struct {
+	Blocks   int64   `json:"blocks"`
+	Count    int     `json:"count"`
+	Ratio    float64 `json:"ratio"`
+	Overflow bool    `json:"overflow"`
+}
+
+// InclusionReport is the delay of inclusion in blocks; unlike the elapsed
+// time, it does not depend on the block time of network.
+type InclusionReport struct {
+	Payments        int               `json:"payments"` // NOTE number of payments with block heights
+	Distribution    []InclusionBucket `json:"distribution"`
+	MissedNextBlock int               `json:"missed-next-block"`
+	MissedRatio     float64           `json:"missed-ratio"`
+	P50             int64             `json:"p50"`
+	P99             int64             `json:"p99"`
+	Max             int64             `json:"max"`
+}
+
+func NewInclusionReport(records []hotbody.Record) (r InclusionReport) {
+	var blocks []int64
+	for _, record := range records {
+		p, ok := record.(hotbody.RecordPayment)
+		if !ok || p.GetError() != nil {
+			continue
+		}
+
+		if b, found := p.BlocksUntilInclusion(); found {
+			blocks = append(blocks, b)
+		}
+	}
+
+	if len(blocks) < 1 {
+		return
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+
+	r.Payments = len(blocks)
+	counts := map[int64]int{}
+	for _, b := range blocks {
+		if b > 1 {
+			r.MissedNextBlock++
+		}
+		if b > MaxInclusionBuckets {
+			b = MaxInclusionBuckets
+		}
+		counts[b]++
+	}
+
+	// NOTE 0 block can be found, when the block height at submit is newer than
+	// it was
+	first := blocks[0]
+	if first > MaxInclusionBuckets {
+		first = MaxInclusionBuckets
+	}
+	for b := first; b <= blocks[len(blocks)-1] && b <= MaxInclusionBuckets; b++ {
+		r.Distribution = append(r.Distribution, InclusionBucket{
+			Blocks:   b,
+			Count:    counts[b],
+			Ratio:    float64(counts[b]) / float64(len(blocks)),
+			Overflow: b == MaxInclusionBuckets,
+		})
+	}
+
+	at := func(p float64) int64 {
+		i := int(float64(len(blocks))*p/100+0.5) - 1
+		if i < 0 {
+			i = 0
+		}
+		return blocks[i]
+	}
+
+	r.MissedRatio = float64(r.MissedNextBlock) / float64(len(blocks))
+	r.P50 = at(50)
+	r.P99 = at(99)
+	r.Max = blocks[len(blocks)-1]
+
+	return
+}
@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// LatencyBreakdown separates the elapsed time of payments by the `timings`
+// of record; the submit latency is how long the API layer takes and the
+// confirmation latency is how long the consensus takes.
+type LatencyBreakdown struct {
+	Records         int                // NOTE number of payments with timings
+	Confirmed       int                // NOTE number of confirmed payments with timings
+	Signing         *hotbody.Histogram `json:"-"`
+	Submit          *hotbody.Histogram `json:"-"`
+	Confirmation    *hotbody.Histogram `json:"-"`
+	PollingOverhead *hotbody.Histogram `json:"-"`
+	SubmitShare     float64            // NOTE ratio of submit to submit + confirmation
+}
+
+func NewLatencyBreakdown(records []hotbody.Record) (b LatencyBreakdown) {
+	b.Signing = hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+	b.Submit = hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+	b.Confirmation = hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+	b.PollingOverhead = hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures)
+
+	record := func(h *hotbody.Histogram, d time.Duration) {
+		// NOTE the clock can go back
+		if d < 0 {
+			d = 0
+		}
+		h.RecordDuration(d)
+	}
+
+	var submit, confirmation time.Duration
+	for _, r := range records {
+		p, ok := r.(hotbody.RecordPayment)
+		if !ok || p.Timings == nil || len(p.Timings.PostAck) < 1 {
+			continue
+		}
+
+		b.Records++
+		record(b.Signing, p.Timings.Signing())
+		record(b.Submit, p.Timings.Submit())
+
+		if len(p.Timings.Confirmed) < 1 {
+			continue
+		}
+
+		b.Confirmed++
+		record(b.Confirmation, p.Timings.Confirmation())
+		record(b.PollingOverhead, p.Timings.PollingOverhead())
+		submit += p.Timings.Submit()
+		confirmation += p.Timings.Confirmation()
+	}
+
+	if submit+confirmation > 0 {
+		b.SubmitShare = float64(submit) / float64(submit+confirmation)
+	}
+
+	return
+}
@@ -0,0 +1,224 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// ErrNoRecords is returned, when <result log> does not have any payment
+// record.
+var ErrNoRecords = errors.New("no records found")
+
+// maxRecordLineSize is the maximum size of the line of <result log>; the
+// `create-accounts` record can have thousands of addresses.
+const maxRecordLineSize int = 64 * 1024 * 1024
+
+// Log is the loaded <result log>; Records has only the payment records.
+// The merged <result log> has it's Sources.
+type Log struct {
+	Source            string
+	Sources           []Log
+	Config            hotbody.HotterConfig
+	Created           time.Time // NOTE when the config is written, before creating accounts
+	Started           time.Time
+	Ended             time.Time
+	Records           []hotbody.Record
+	CreateAccounts    []hotbody.Record
+	SEBAKErrorRecords []hotbody.Record
+	SEBAKErrors       map[int]int
+	Controls          []hotbody.Record
+	Accounts          []string // NOTE created accounts
+	StoppedAccounts   []hotbody.Record
+	Others            []hotbody.Record // NOTE records of the registered custom types
+	Version           int              // NOTE schema version
+	Run               string
+	MissingRecords    int     // NOTE found by the gap of sequence numbers
+	Window            *Window // NOTE filtered by window
+	unknownTypes      map[string]bool
+	lastSeq           uint64
+}
+
+// Load reads the records from <result log>; it returns ErrNoRecords, when no
+// payment record is found.
+func Load(r io.Reader) (l Log, err error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxRecordLineSize)
+	sc.Split(bufio.ScanLines)
+
+	sc.Scan()
+	if err = l.LoadConfig(sc.Text()); err != nil {
+		return
+	}
+	log.Debug("config loaded", "config", l.Config)
+
+	log.Debug("trying to load record")
+	for sc.Scan() {
+		if err = l.LoadRecord(sc.Text()); err != nil {
+			return
+		}
+	}
+	log.Debug("records loaded", "count", len(l.Records))
+
+	if err = sc.Err(); err != nil {
+		err = fmt.Errorf("something wrong to read <result log>; %v", err)
+		return
+	}
+
+	if len(l.Records) < 1 {
+		err = ErrNoRecords
+		return
+	}
+
+	return
+}
+
+// LoadConfig loads the first line of <result log>, config.
+func (l *Log) LoadConfig(headLine string) (err error) {
+	var record hotbody.Record
+	if record, err = hotbody.DecodeRecord([]byte(headLine)); err != nil {
+		return fmt.Errorf("something wrong to read <result log>; %v; %v", err, headLine)
+	}
+
+	config, ok := record.(hotbody.HotterConfig)
+	if !ok {
+		return fmt.Errorf("something wrong to read <result log>; config not found; %v", headLine)
+	}
+	l.Config = config
+
+	var head hotbody.BaseRecord
+	if json.Unmarshal([]byte(headLine), &head) == nil {
+		l.Created = head.GetTime()
+		l.Version = head.Version
+		l.Run = head.Run
+		l.lastSeq = head.Seq
+	}
+
+	l.SEBAKErrors = map[int]int{}
+
+	return
+}
+
+// LoadRecord loads the line of <result log> after config.
+func (l *Log) LoadRecord(s string) (err error) {
+	var record hotbody.Record
+	if record, err = hotbody.DecodeRecord([]byte(s)); err != nil {
+		// NOTE the unknown record type is skipped, it may come from the newer
+		// `go`.
+		if e, ok := err.(*hotbody.ErrorUnknownRecordType); ok {
+			if l.unknownTypes == nil {
+				l.unknownTypes = map[string]bool{}
+			}
+			if !l.unknownTypes[e.Type] {
+				log.Warn("unknown record type found; skipped", "type", e.Type)
+				l.unknownTypes[e.Type] = true
+			}
+			return nil
+		}
+		return fmt.Errorf("something wrong to read <result log>; %v; %v", err, s)
+	} else if record == nil {
+		return
+	}
+
+	l.AddRecord(record)
+
+	return
+}
+
+// AddRecord adds the decoded record; the sequence number is checked to count
+// the missing records.
+func (l *Log) AddRecord(record hotbody.Record) {
+	if s, ok := record.(interface {
+		GetSequence() (uint64, bool)
+	}); ok {
+		if seq, found := s.GetSequence(); found {
+			if seq > l.lastSeq+1 {
+				l.MissingRecords += int(seq - l.lastSeq - 1)
+			}
+			l.lastSeq = seq
+		}
+	}
+
+	if l.SEBAKErrors == nil {
+		l.SEBAKErrors = map[int]int{}
+	}
+
+	switch record.GetType() {
+	case "started":
+		l.Started = record.GetTime()
+	case "ended":
+		l.Ended = record.GetTime()
+	case "create-accounts":
+		l.CreateAccounts = append(l.CreateAccounts, record)
+		if ca, ok := record.(hotbody.RecordCreateAccounts); ok && ca.GetError() == nil {
+			l.Accounts = append(l.Accounts, ca.Addresses...)
+		}
+	case "account-stopped":
+		l.StoppedAccounts = append(l.StoppedAccounts, record)
+	case "sebak-error":
+		l.SEBAKErrorRecords = append(l.SEBAKErrorRecords, record)
+		if code, ok := SEBAKErrorCode(record); ok {
+			l.SEBAKErrors[code]++
+		}
+	case "payment":
+		l.Records = append(l.Records, record)
+	case "control":
+		l.Controls = append(l.Controls, record)
+	default:
+		l.Others = append(l.Others, record)
+	}
+}
+
+// Begin returns the time of `started`; without it, the time when the first
+// payment was sent.
+func (l Log) Begin() time.Time {
+	if !l.Started.IsZero() || len(l.Records) < 1 {
+		return l.Started
+	}
+
+	return l.Records[0].GetTime().Add(-time.Duration(l.Records[0].GetElapsed()))
+}
+
+// SEBAKErrorCode returns the SEBAK error code of `sebak-error` record.
+func SEBAKErrorCode(record hotbody.Record) (code int, found bool) {
+	sr, ok := record.(hotbody.RecordSEBAKError)
+	if !ok {
+		return
+	}
+
+	c := sr.GetErrorClass()
+	if c.Class != hotbody.ErrorClassSEBAK {
+		return
+	}
+
+	return c.Code, true
+}
+
+// SortRecordsByTime sorts the records by it's time; the time is parsed only
+// once for each record.
+func SortRecordsByTime(records []hotbody.Record) {
+	times := make([]time.Time, len(records))
+	for i, r := range records {
+		times[i] = r.GetTime()
+	}
+
+	index := make([]int, len(records))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return times[index[i]].Before(times[index[j]])
+	})
+
+	sorted := make([]hotbody.Record, len(records))
+	for i, j := range index {
+		sorted[i] = records[j]
+	}
+	copy(records, sorted)
+}
@@ -0,0 +1,98 @@
+package analysis
+
+import (
+	"fmt"
+)
+
+// Merge merges the <result log>s of the `go`s, which ran at the same time;
+// all of them must have the same network id. The merged config is the config
+// of the first source, but `T` is the sum of all and the timeout is the
+// longest one. Without logs, it returns ErrNoRecords.
+func Merge(logs []Log) (l Log, err error) {
+	if len(logs) < 1 {
+		err = ErrNoRecords
+		return
+	}
+
+	networkID := logs[0].Config.Node.Policy.NetworkID
+	for _, s := range logs[1:] {
+		if s.Config.Node.Policy.NetworkID != networkID {
+			err = fmt.Errorf(
+				"network id does not match; '%s' of %s, but '%s' of %s",
+				networkID,
+				logs[0].Source,
+				s.Config.Node.Policy.NetworkID,
+				s.Source,
+			)
+			return
+		}
+	}
+
+	l.Config = logs[0].Config
+	l.Config.T = 0
+	l.Config.ResultOutput = ""
+	l.Version = logs[0].Version
+	l.SEBAKErrors = map[int]int{}
+
+	for _, s := range logs {
+		l.Sources = append(l.Sources, s)
+
+		l.Config.T += s.Config.T
+		if s.Config.Timeout > l.Config.Timeout {
+			l.Config.Timeout = s.Config.Timeout
+		}
+
+		if !s.Created.IsZero() && (l.Created.IsZero() || s.Created.Before(l.Created)) {
+			l.Created = s.Created
+		}
+		if !s.Started.IsZero() && (l.Started.IsZero() || s.Started.Before(l.Started)) {
+			l.Started = s.Started
+		}
+		if s.Ended.After(l.Ended) {
+			l.Ended = s.Ended
+		}
+
+		l.Records = append(l.Records, s.Records...)
+		l.CreateAccounts = append(l.CreateAccounts, s.CreateAccounts...)
+		l.SEBAKErrorRecords = append(l.SEBAKErrorRecords, s.SEBAKErrorRecords...)
+		l.Controls = append(l.Controls, s.Controls...)
+		l.Accounts = append(l.Accounts, s.Accounts...)
+		l.StoppedAccounts = append(l.StoppedAccounts, s.StoppedAccounts...)
+		l.Others = append(l.Others, s.Others...)
+		l.MissingRecords += s.MissingRecords
+		for code, count := range s.SEBAKErrors {
+			l.SEBAKErrors[code] += count
+		}
+
+		if s.Window == nil {
+			continue
+		}
+		if l.Window == nil {
+			w := *s.Window
+			l.Window = &w
+			continue
+		}
+		if s.Window.Since.Before(l.Window.Since) {
+			l.Window.Since = s.Window.Since
+		}
+		if s.Window.Until.After(l.Window.Until) {
+			l.Window.Until = s.Window.Until
+		}
+		l.Window.Excluded += s.Window.Excluded
+	}
+
+	SortRecordsByTime(l.Records)
+	SortRecordsByTime(l.CreateAccounts)
+	SortRecordsByTime(l.SEBAKErrorRecords)
+	// NOTE the controls are applied in order, like `extend`, so they must be
+	// in time order across the sources
+	SortRecordsByTime(l.Controls)
+	SortRecordsByTime(l.StoppedAccounts)
+	SortRecordsByTime(l.Others)
+
+	if len(l.Records) < 1 {
+		err = ErrNoRecords
+	}
+
+	return
+}
@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+func newMergeLog(source, networkID string, t int, timeout time.Duration, started time.Time) Log {
+	l := Log{
+		Source:  source,
+		Started: started,
+		Ended:   started.Add(timeout),
+		Records: []hotbody.Record{newPaymentRecord(started.Add(time.Second))},
+	}
+	l.Config.Node.Policy.NetworkID = networkID
+	l.Config.T = t
+	l.Config.Timeout = timeout
+
+	return l
+}
+
+func TestMerge(t *testing.T) {
+	now := time.Date(2018, 11, 4, 16, 36, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		logs    []Log
+		t       int
+		timeout time.Duration
+		started time.Time
+		ended   time.Time
+		err     error
+	}{
+		{name: "zero logs", logs: nil, err: ErrNoRecords},
+		{name: "empty logs", logs: []Log{}, err: ErrNoRecords},
+		{
+			name:    "one log",
+			logs:    []Log{newMergeLog("a", "network", 10, time.Minute, now)},
+			t:       10,
+			timeout: time.Minute,
+			started: now,
+			ended:   now.Add(time.Minute),
+		},
+		{
+			name: "two logs",
+			logs: []Log{
+				newMergeLog("a", "network", 10, time.Minute, now.Add(time.Second)),
+				newMergeLog("b", "network", 5, 2*time.Minute, now),
+			},
+			t:       15,
+			timeout: 2 * time.Minute,
+			started: now,
+			ended:   now.Add(2 * time.Minute),
+		},
+	}
+
+	for _, c := range cases {
+		l, err := Merge(c.logs)
+		if err != c.err {
+			t.Errorf("%s: expected error %v, but %v", c.name, c.err, err)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		if l.Config.T != c.t {
+			t.Errorf("%s: T expected %d, but %d", c.name, c.t, l.Config.T)
+		}
+		if l.Config.Timeout != c.timeout {
+			t.Errorf("%s: timeout expected %v, but %v", c.name, c.timeout, l.Config.Timeout)
+		}
+		if !l.Started.Equal(c.started) || !l.Ended.Equal(c.ended) {
+			t.Errorf("%s: expected %v ~ %v, but %v ~ %v", c.name, c.started, c.ended, l.Started, l.Ended)
+		}
+		if len(l.Sources) != len(c.logs) || len(l.Records) != len(c.logs) {
+			t.Errorf("%s: expected %d sources and records, but %d and %d", c.name, len(c.logs), len(l.Sources), len(l.Records))
+		}
+	}
+}
+
+func TestMergeNetworkIDMismatch(t *testing.T) {
+	now := time.Now()
+	_, err := Merge([]Log{
+		newMergeLog("a", "network", 10, time.Minute, now),
+		newMergeLog("b", "other network", 10, time.Minute, now),
+	})
+	if err == nil {
+		t.Error("expected error of network id")
+	}
+}
+
+func TestMergeSortsRecords(t *testing.T) {
+	now := time.Date(2018, 11, 4, 16, 36, 0, 0, time.UTC)
+
+	a := newMergeLog("a", "network", 1, time.Minute, now)
+	b := newMergeLog("b", "network", 1, time.Minute, now)
+	a.Controls = []hotbody.Record{newControlRecord(now.Add(2 * time.Second))}
+	b.Controls = []hotbody.Record{newControlRecord(now.Add(time.Second))}
+
+	l, err := Merge([]Log{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Controls) != 2 || l.Controls[0].GetTime().After(l.Controls[1].GetTime()) {
+		t.Errorf("controls are not sorted by time")
+	}
+}
+
+func newControlRecord(t time.Time) hotbody.Record {
+	return hotbody.RecordControl{
+		BaseRecord: hotbody.BaseRecord{Time: hotbody.FormatRecordTime(t), Type: "control"},
+		Action:     "pause",
+	}
+}
+
+func newPaymentRecord(t time.Time) hotbody.Record {
+	return hotbody.RecordPayment{
+		BaseResultRecord: hotbody.BaseResultRecord{
+			BaseRecord: hotbody.BaseRecord{Time: hotbody.FormatRecordTime(t), Type: "payment"},
+		},
+	}
+}
@@ -0,0 +1,302 @@
+package analysis
+
+import (
+	"io"
+	"time"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+)
+
+// DefaultNumberOfBuckets is the number of buckets of the distribution of
+// elapsed time, when the bucket width is not given.
+const DefaultNumberOfBuckets int = 10
+
+// NumberOfErrorSamples is the maximum number of sample transactions of each
+// error type.
+const NumberOfErrorSamples int = 5
+
+type DistributionBucket struct {
+	Low   time.Duration `json:"low"`
+	High  time.Duration `json:"high"`
+	Count int64         `json:"count"`
+	Ratio float64       `json:"ratio"`
+}
+
+// Provisioning is the summary of creating accounts; most of them are created
+// before `started`, but with changing concurrency thru control API, accounts
+// can be created while running.
+type Provisioning struct {
+	Transactions  int                  `json:"transactions"`
+	Accounts      int                  `json:"accounts"`
+	Errors        int                  `json:"errors"`
+	Histogram     *hotbody.Histogram   `json:"-"`
+	BucketWidth   time.Duration        `json:"bucket-width"`
+	Distribution  []DistributionBucket `json:"distribution"`
+	SetupDuration time.Duration        `json:"setup-duration"` // NOTE from config to `started`
+}
+
+func NewProvisioning(l Log) Provisioning {
+	p := Provisioning{
+		Histogram: hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures),
+	}
+
+	for _, r := range l.CreateAccounts {
+		p.Transactions++
+		if r.GetError() != nil {
+			p.Errors++
+			continue
+		}
+
+		p.Histogram.Record(r.GetElapsed())
+		if ca, ok := r.(hotbody.RecordCreateAccounts); ok {
+			p.Accounts += int(ca.Count)
+		}
+	}
+
+	// NOTE the transaction, which failed to be sent, is recorded only in
+	// `sebak-error`.
+	for _, r := range l.SEBAKErrorRecords {
+		if sr, ok := r.(hotbody.RecordSEBAKError); ok && sr.When == "create-account" {
+			p.Transactions++
+			p.Errors++
+		}
+	}
+
+	p.BucketWidth = hotbody.AutoBucketWidth(p.Histogram.Max(), DefaultNumberOfBuckets)
+	p.Distribution = NewDistribution(p.Histogram, p.BucketWidth)
+
+	if !l.Created.IsZero() && !l.Started.IsZero() {
+		p.SetupDuration = l.Started.Sub(l.Created)
+	}
+
+	return p
+}
+
+func NewDistribution(h *hotbody.Histogram, width time.Duration) (distribution []DistributionBucket) {
+	if h.Count() < 1 {
+		return
+	}
+
+	for low := int64(0); low <= h.Max(); low += int64(width) {
+		c := h.CountBetween(low, low+int64(width))
+		distribution = append(distribution, DistributionBucket{
+			Low:   time.Duration(low),
+			High:  time.Duration(low) + width,
+			Count: c,
+			Ratio: float64(c) / float64(h.Count()),
+		})
+	}
+
+	return
+}
+
+// Options is the options of Summary.
+type Options struct {
+	BucketWidth time.Duration // NOTE if 0, decided by the max elapsed time
+}
+
+// Summary is the analyzed result of <result log>; the config, time,
+// throughput, elapsed time of payments and errors.
+type Summary struct {
+	Config         hotbody.HotterConfig
+	Run            string
+	Version        int
+	MissingRecords int
+	Window         *Window
+	Records        []hotbody.Record // NOTE payment records
+
+	Started      time.Time
+	Ended        time.Time // NOTE time of the last payment or the end of window
+	TotalElapsed time.Duration
+
+	Requests            int
+	Operations          int
+	ConfirmedOperations int
+	Errors              int
+	ErrorRate           float64
+	TPS                 float64 // NOTE confirmed payments per second
+	ExpectedOPS         float64
+	RealOPS             float64 // NOTE confirmed operations per second
+
+	Histogram    *hotbody.Histogram // NOTE elapsed time of payments
+	BucketWidth  time.Duration
+	Distribution []DistributionBucket
+
+	ErrorTypes        map[hotbody.RecordErrorType]int
+	ErrorSamples      map[hotbody.RecordErrorType][]string // NOTE transaction hashes
+	SEBAKErrors       map[int]int
+	SEBAKErrorSamples map[int][]string // NOTE transaction hashes
+	ErrorInfos        map[hotbody.RecordErrorType]hotbody.ErrorInfo
+	SEBAKErrorInfos   map[int]hotbody.ErrorInfo
+	ErrorCategories   map[hotbody.ErrorCategory]int
+	RetryableErrors   int
+
+	Provisioning Provisioning
+	Accounts     AccountReport
+	Latency      LatencyBreakdown
+	Inclusion    InclusionReport
+	Aggregators  []hotbody.RecordAggregator // NOTE registered aggregators, which have the records
+}
+
+// Analyze reads <result log> and returns the summary of the records in the
+// window of filter.
+func Analyze(r io.Reader, f Filter, o Options) (s Summary, err error) {
+	var l Log
+	if l, err = Load(r); err != nil {
+		return
+	}
+	if l, err = FilterLog(l, f); err != nil {
+		return
+	}
+
+	return NewSummary(l, o), nil
+}
+
+// NewSummary analyzes the loaded <result log>.
+func NewSummary(l Log, o Options) Summary {
+	s := Summary{
+		Config:            l.Config,
+		Run:               l.Run,
+		Version:           l.Version,
+		MissingRecords:    l.MissingRecords,
+		Window:            l.Window,
+		Records:           l.Records,
+		Histogram:         hotbody.NewHistogram(hotbody.DefaultHistogramSignificantFigures),
+		ErrorTypes:        map[hotbody.RecordErrorType]int{},
+		ErrorSamples:      map[hotbody.RecordErrorType][]string{},
+		SEBAKErrors:       l.SEBAKErrors,
+		SEBAKErrorSamples: map[int][]string{},
+		ErrorInfos:        map[hotbody.RecordErrorType]hotbody.ErrorInfo{},
+		SEBAKErrorInfos:   map[int]hotbody.ErrorInfo{},
+		ErrorCategories:   map[hotbody.ErrorCategory]int{},
+	}
+	if s.SEBAKErrors == nil {
+		s.SEBAKErrors = map[int]int{}
+	}
+
+	for _, r := range l.Records {
+		s.Histogram.Record(r.GetElapsed())
+
+		operations := RecordOperations(r, l.Config.Operations)
+		s.Operations += operations
+		if r.GetError() == nil {
+			s.ConfirmedOperations += operations
+			continue
+		}
+		s.Errors++
+		s.ErrorTypes[r.GetErrorType()]++
+
+		info := hotbody.ExplainError(r.GetErrorClass())
+		s.ErrorInfos[r.GetErrorType()] = info
+		s.ErrorCategories[info.Category]++
+		if info.Retryable {
+			s.RetryableErrors++
+		}
+
+		samples := s.ErrorSamples[r.GetErrorType()]
+		if payment, ok := r.(hotbody.RecordPayment); ok && len(samples) < NumberOfErrorSamples {
+			s.ErrorSamples[r.GetErrorType()] = append(samples, payment.Transaction)
+		}
+	}
+
+	for _, r := range l.SEBAKErrorRecords {
+		code, ok := SEBAKErrorCode(r)
+		if !ok {
+			continue
+		}
+		if _, found := s.SEBAKErrorInfos[code]; !found {
+			s.SEBAKErrorInfos[code] = hotbody.ExplainError(r.GetErrorClass())
+		}
+
+		samples := s.SEBAKErrorSamples[code]
+		if sr, ok := r.(hotbody.RecordSEBAKError); ok && len(samples) < NumberOfErrorSamples {
+			s.SEBAKErrorSamples[code] = append(samples, sr.Transaction)
+		}
+	}
+	for code := range s.SEBAKErrors {
+		if _, found := s.SEBAKErrorInfos[code]; !found {
+			s.SEBAKErrorInfos[code] = hotbody.ExplainError(
+				hotbody.RecordErrorClass{Class: hotbody.ErrorClassSEBAK, Code: code},
+			)
+		}
+	}
+
+	s.Provisioning = NewProvisioning(l)
+	s.Accounts = NewAccountReport(l)
+	s.Latency = NewLatencyBreakdown(l.Records)
+	s.Inclusion = NewInclusionReport(l.Records)
+	s.Aggregators = aggregate(l)
+
+	s.BucketWidth = o.BucketWidth
+	if s.BucketWidth < 1 {
+		s.BucketWidth = hotbody.AutoBucketWidth(s.Histogram.Max(), DefaultNumberOfBuckets)
+	}
+	s.Distribution = NewDistribution(s.Histogram, s.BucketWidth)
+
+	s.Started = l.Begin()
+	switch {
+	case l.Window != nil:
+		s.Started = l.Window.Since
+		s.Ended = l.Window.Until
+	case len(l.Records) > 0:
+		s.Ended = l.Records[len(l.Records)-1].GetTime()
+	default:
+		s.Ended = l.Ended
+	}
+
+	s.Requests = len(l.Records)
+	s.TotalElapsed = s.Ended.Sub(s.Started)
+	s.ErrorRate = ErrorRatio(s.Errors, s.Requests)
+	if seconds := s.TotalElapsed.Seconds(); seconds > 0 {
+		s.TPS = float64(s.Requests-s.Errors) / seconds
+		s.ExpectedOPS = float64(s.Operations) / seconds
+		s.RealOPS = float64(s.ConfirmedOperations) / seconds
+	}
+
+	return s
+}
+
+// aggregate passes the records to the registered aggregators in time order.
+func aggregate(l Log) []hotbody.RecordAggregator {
+	aggregators := hotbody.NewRecordAggregators()
+	if len(aggregators.Aggregators()) < 1 {
+		return nil
+	}
+
+	var records []hotbody.Record
+	for _, r := range [][]hotbody.Record{
+		l.CreateAccounts,
+		l.Records,
+		l.SEBAKErrorRecords,
+		l.Controls,
+		l.StoppedAccounts,
+		l.Others,
+	} {
+		records = append(records, r...)
+	}
+	SortRecordsByTime(records)
+
+	for _, record := range records {
+		aggregators.Add(record)
+	}
+
+	return aggregators.Aggregators()
+}
+
+// RecordOperations returns the number of operations of payment record; the
+// record has it as `count`.
+func RecordOperations(r hotbody.Record, defaultOperations int) int {
+	if p, ok := r.(hotbody.RecordPayment); ok && p.Count > 0 {
+		return int(p.Count)
+	}
+
+	return defaultOperations
+}
+
+func ErrorRatio(count, total int) float64 {
+	if total < 1 {
+		return 0
+	}
+
+	return float64(count) / float64(total)
+}
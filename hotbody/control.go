@@ -0,0 +1,486 @@
+package hotbody
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	logging "github.com/inconshreveable/log15"
+)
+
+var errorHotterNotStarted = fmt.Errorf("hotter is not started yet")
+
+// acquire occupies one slot of concurrency. It returns false when paused or
+// the concurrency is already full.
+func (h *Hotter) acquire() bool {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.paused || h.inflight >= h.T {
+		return false
+	}
+	h.inflight++
+
+	return true
+}
+
+func (h *Hotter) release() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.inflight--
+}
+
+func (h *Hotter) park(address string) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.idle = append(h.idle, address)
+}
+
+// waitRate waits until the next transaction can be sent under the rate.
+func (h *Hotter) waitRate() {
+	h.Lock()
+	if h.rate < 1 {
+		h.lastDispatched = time.Now()
+		h.Unlock()
+		return
+	}
+
+	next := h.lastDispatched.Add(time.Second / time.Duration(h.rate))
+	if now := time.Now(); next.Before(now) {
+		next = now
+	}
+	h.lastDispatched = next
+	h.Unlock()
+
+	time.Sleep(time.Until(next))
+}
+
+// dispatchIdle dispatches the idle accounts as many as the free slots of
+// concurrency.
+func (h *Hotter) dispatchIdle() {
+	h.Lock()
+	n := h.T - h.inflight
+	if n > len(h.idle) {
+		n = len(h.idle)
+	}
+	if h.paused || n < 1 {
+		h.Unlock()
+		return
+	}
+
+	addresses := make([]string, n)
+	copy(addresses, h.idle[:n])
+	h.idle = h.idle[n:]
+	h.Unlock()
+
+	for _, address := range addresses {
+		go func(a string) {
+			h.run <- a
+		}(address)
+	}
+}
+
+func (h *Hotter) isStarted() bool {
+	h.RLock()
+	defer h.RUnlock()
+
+	return !h.started.IsZero()
+}
+
+func (h *Hotter) Pause() error {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	}
+
+	h.Lock()
+	h.paused = true
+	h.Unlock()
+
+	h.result.Write("control", "action", "pause")
+
+	return nil
+}
+
+func (h *Hotter) Resume() error {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	}
+
+	h.Lock()
+	h.paused = false
+	h.Unlock()
+
+	h.result.Write("control", "action", "resume")
+	h.dispatchIdle()
+
+	return nil
+}
+
+// SetConcurrency changes the number of concurrent transactions. If the
+// existing accounts are not enough, new accounts will be created.
+func (h *Hotter) SetConcurrency(n int) (err error) {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	} else if n < 1 {
+		return fmt.Errorf("concurrency must be bigger than 0")
+	}
+
+	h.RLock()
+	numberOfAccounts := len(h.createdAccounts)
+	previous := h.T
+	h.RUnlock()
+
+	if n+1 > numberOfAccounts {
+		var created []string
+		if created, err = h.prepareAccounts(context.Background(), n+1-numberOfAccounts); err != nil {
+			return
+		}
+
+		for _, address := range created {
+			h.park(address)
+		}
+	}
+
+	h.Lock()
+	h.T = n
+	h.Unlock()
+
+	h.result.Write("control", "action", "concurrency", "value", n, "previous", previous)
+	h.dispatchIdle()
+
+	return
+}
+
+// SetRate limits the number of transactions sent in one second; 0 means
+// unlimited.
+func (h *Hotter) SetRate(n int) error {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	} else if n < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+
+	h.Lock()
+	previous := h.rate
+	h.rate = n
+	h.Unlock()
+
+	h.result.Write("control", "action", "rate", "value", n, "previous", previous)
+
+	return nil
+}
+
+func (h *Hotter) SetOperations(n int) (err error) {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	} else if n < 1 {
+		return fmt.Errorf("operations must be bigger than 0")
+	} else if n > h.Node.Policy.OperationsLimit {
+		return fmt.Errorf("operations must not be bigger than operations limit, %d", h.Node.Policy.OperationsLimit)
+	}
+
+	h.RLock()
+	numberOfAccounts := len(h.createdAccounts)
+	previous := h.Operations
+	h.RUnlock()
+
+	if n+1 > numberOfAccounts {
+		var created []string
+		if created, err = h.prepareAccounts(context.Background(), n+1-numberOfAccounts); err != nil {
+			return
+		}
+
+		for _, address := range created {
+			h.park(address)
+		}
+	}
+
+	h.Lock()
+	h.Operations = n
+	h.Unlock()
+
+	h.result.Write("control", "action", "operations", "value", n, "previous", previous)
+
+	return
+}
+
+func (h *Hotter) AddEndpoint(u *url.URL) (err error) {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	}
+
+	h.Lock()
+	for _, client := range h.clients {
+		if client.URL().String() == u.String() {
+			h.Unlock()
+			return fmt.Errorf("endpoint already added: %v", u)
+		}
+	}
+	headers := h.clients[0].headers
+	maxIdleConnsPerHost := h.clients[0].Transport().MaxIdleConnsPerHost
+	h.Unlock()
+
+	var client *HTTP2Client
+	if client, err = NewHTTP2Client(h.RequestTimeout, u, headers); err != nil {
+		return
+	}
+	client.Transport().MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	h.Lock()
+	h.clients = append(h.clients, client)
+	h.Unlock()
+
+	h.result.Write("control", "action", "add-endpoint", "value", u.String())
+
+	return
+}
+
+func (h *Hotter) RemoveEndpoint(u *url.URL) error {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	}
+
+	h.Lock()
+
+	var clients []*HTTP2Client
+	for _, client := range h.clients {
+		if client.URL().String() == u.String() {
+			continue
+		}
+		clients = append(clients, client)
+	}
+
+	if len(clients) == len(h.clients) {
+		h.Unlock()
+		return fmt.Errorf("endpoint not found: %v", u)
+	} else if len(clients) < 1 {
+		h.Unlock()
+		return fmt.Errorf("at least one endpoint must be left")
+	}
+	h.clients = clients
+	h.Unlock()
+
+	h.result.Write("control", "action", "remove-endpoint", "value", u.String())
+
+	return nil
+}
+
+// Extend extends the running time.
+func (h *Hotter) Extend(d time.Duration) error {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	}
+
+	h.Lock()
+	h.deadline = h.deadline.Add(d)
+	h.Timeout = h.deadline.Sub(h.started)
+	timeout := h.Timeout
+	h.Unlock()
+
+	h.notifyDeadline()
+	h.result.Write("control", "action", "extend", "value", d, "timeout", timeout)
+
+	return nil
+}
+
+// End stops running immediately; the existing requests will be finished.
+func (h *Hotter) End() error {
+	h.controlLock.Lock()
+	defer h.controlLock.Unlock()
+
+	if !h.isStarted() {
+		return errorHotterNotStarted
+	}
+
+	h.Lock()
+	h.deadline = time.Now()
+	h.Timeout = h.deadline.Sub(h.started)
+	h.Unlock()
+
+	h.notifyDeadline()
+	h.result.Write("control", "action", "end")
+
+	return nil
+}
+
+func (h *Hotter) notifyDeadline() {
+	select {
+	case h.deadlineChanged <- true:
+	default:
+	}
+}
+
+// ControlServer serves the control API of the running hotter thru http. The
+// address can be tcp address, `127.0.0.1:12346` or unix socket,
+// `unix:///tmp/hot-body.sock`.
+//
+//	GET    /status
+//	POST   /pause
+//	POST   /resume
+//	POST   /concurrency?n=<number>
+//	POST   /rate?n=<number of transactions per second>
+//	POST   /operations?n=<number>
+//	POST   /endpoints?endpoint=<endpoint>
+//	DELETE /endpoints?endpoint=<endpoint>
+//	POST   /extend?duration=<duration>
+//	POST   /end
+type ControlServer struct {
+	hotter   *Hotter
+	address  string
+	listener net.Listener
+	server   *http.Server
+	log      logging.Logger
+}
+
+func NewControlServer(hotter *Hotter, address string) (c *ControlServer, err error) {
+	c = &ControlServer{
+		hotter:  hotter,
+		address: address,
+		log:     log.New(logging.Ctx{"m": "control"}),
+	}
+
+	if strings.HasPrefix(address, "unix://") {
+		path := strings.TrimPrefix(address, "unix://")
+		os.Remove(path)
+		c.listener, err = net.Listen("unix", path)
+	} else {
+		c.listener, err = net.Listen("tcp", address)
+	}
+	if err != nil {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", c.handleStatus)
+	mux.HandleFunc("/pause", c.handle(func(*http.Request) error { return hotter.Pause() }))
+	mux.HandleFunc("/resume", c.handle(func(*http.Request) error { return hotter.Resume() }))
+	mux.HandleFunc("/concurrency", c.handle(func(r *http.Request) error {
+		n, err := strconv.Atoi(r.URL.Query().Get("n"))
+		if err != nil {
+			return fmt.Errorf("invalid 'n'; %v", err)
+		}
+		return hotter.SetConcurrency(n)
+	}))
+	mux.HandleFunc("/rate", c.handle(func(r *http.Request) error {
+		n, err := strconv.Atoi(r.URL.Query().Get("n"))
+		if err != nil {
+			return fmt.Errorf("invalid 'n'; %v", err)
+		}
+		return hotter.SetRate(n)
+	}))
+	mux.HandleFunc("/operations", c.handle(func(r *http.Request) error {
+		n, err := strconv.Atoi(r.URL.Query().Get("n"))
+		if err != nil {
+			return fmt.Errorf("invalid 'n'; %v", err)
+		}
+		return hotter.SetOperations(n)
+	}))
+	mux.HandleFunc("/endpoints", c.handle(func(r *http.Request) error {
+		u, err := url.Parse(r.URL.Query().Get("endpoint"))
+		if err != nil || len(u.Host) < 1 {
+			return fmt.Errorf("invalid 'endpoint'; %v", r.URL.Query().Get("endpoint"))
+		}
+		if r.Method == http.MethodDelete {
+			return hotter.RemoveEndpoint(u)
+		}
+		return hotter.AddEndpoint(u)
+	}))
+	mux.HandleFunc("/extend", c.handle(func(r *http.Request) error {
+		d, err := time.ParseDuration(r.URL.Query().Get("duration"))
+		if err != nil {
+			return fmt.Errorf("invalid 'duration'; %v", err)
+		}
+		return hotter.Extend(d)
+	}))
+	mux.HandleFunc("/end", c.handle(func(*http.Request) error { return hotter.End() }))
+
+	c.server = &http.Server{Handler: mux}
+
+	return
+}
+
+func (c *ControlServer) Start() {
+	c.log.Debug("control server started", "address", c.address)
+
+	go func() {
+		if err := c.server.Serve(c.listener); err != nil && err != http.ErrServerClosed {
+			c.log.Error("control server stopped", "error", err)
+		}
+	}()
+}
+
+func (c *ControlServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return c.server.Shutdown(ctx)
+}
+
+func (c *ControlServer) handle(f func(*http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			c.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+			return
+		}
+
+		c.log.Debug("control requested", "path", r.URL.String(), "method", r.Method)
+		if err := f(r); err != nil {
+			c.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		c.handleStatus(w, r)
+	}
+}
+
+func (c *ControlServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(c.hotter.Status())
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func (c *ControlServer) writeError(w http.ResponseWriter, status int, err error) {
+	b, _ := json.Marshal(map[string]string{"error": err.Error()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
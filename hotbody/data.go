@@ -71,6 +71,7 @@ type Transaction struct {
 	OperationCount uint64        `json:"operation_count"`
 	SequenceID     uint64        `json:"sequenceid"`
 	Source         string        `json:"source"`
+	Block          string        `json:"block"` // NOTE hash of the block, which contains the transaction
 	/*
 		OperationsURL  string        `json:"operations-url"`
 		AccountURL     string        `json:"account-url"`
@@ -103,6 +104,19 @@ func (ctx Transaction) String() string {
 	return string(s)
 }
 
+// Block is the block, which contains the transactions.
+type Block struct {
+	Hash      string `json:"hash"`
+	Height    uint64 `json:"height"`
+	Confirmed string `json:"confirmed"`
+	TotalTxs  uint64 `json:"total-txs"`
+}
+
+func NewBlockFromJSON(b []byte) (block Block, err error) {
+	err = json.Unmarshal(b, &block)
+	return
+}
+
 type SortByBlockAccountBalance []BlockAccount
 
 func (s SortByBlockAccountBalance) Len() int {
@@ -190,10 +204,10 @@ func PickKeysRandom(addresses []string, n int, excludes ...string) []string {
 	return founds
 }
 
-func PickKeysRandom2(addresses []string, n int) []string {
+func PickKeysRandom2(r *SeededRand, addresses []string, n int) []string {
 	if len(addresses) > n {
 		shuffled := make([]string, len(addresses))
-		for i, v := range rand.Perm(len(addresses)) {
+		for i, v := range r.Perm(len(addresses)) {
 			if i >= n {
 				break
 			}
@@ -209,162 +223,182 @@ func PickKeysRandom2(addresses []string, n int) []string {
 type Record interface {
 	GetTime() time.Time
 	GetType() string
-	GetElapsed() int64
+	GetElapsed() int64 // NOTE nanoseconds
 	GetError() error
 	GetErrorType() RecordErrorType
+	GetErrorClass() RecordErrorClass
 	GetRawError() map[string]interface{}
 }
 
 type RecordErrorType string
 
-const (
-	RecordErrorUnknown         RecordErrorType = "unknown"
-	RecordErrorECONNRESET      RecordErrorType = "reset-by-peer"
-	RecordErrorTxDoesNotExist  RecordErrorType = "tx-not-found"
-	RecordErrorSameSourceFound RecordErrorType = "same-source-found"
-	RecordErrorNetworkError    RecordErrorType = "network-problem"
-)
+const RecordErrorUnknown RecordErrorType = "unknown"
 
 /*
-{
-    "addresses": [
-        "GBXUE3BYSLTDG74BJSPQKRIBK2KBFJLJVFOZP5LT6VO262UXCXPQMY5V"
-    ],
-    "count": 300,
-    "elapsed": "1.9459782410",
-    "error": null,
-    "type": "create-accounts"
-}
+	{
+	    "addresses": [
+	        "GBXUE3BYSLTDG74BJSPQKRIBK2KBFJLJVFOZP5LT6VO262UXCXPQMY5V"
+	    ],
+	    "count": 300,
+	    "elapsed": "1.9459782410",
+	    "error": null,
+	    "type": "create-accounts"
+	}
 */
 type RecordCreateAccounts struct {
-	Time      string                 `json:"time"`
-	Type      string                 `json:"type"`
-	Addresses []string               `json:"addresses"`
-	Count     uint64                 `json:"count"`
-	Elapsed   string                 `json:"elapsed"`
-	Error     map[string]interface{} `json:"error"`
+	BaseResultRecord
+	Addresses   []string `json:"addresses"`
+	Count       uint64   `json:"count"`
+	Transaction string   `json:"transaction"`
 }
 
-func (r RecordCreateAccounts) GetTime() time.Time {
-	t, _ := common.ParseISO8601(r.Time)
-	return t
-}
+/*
+	{
+	    "addresses": [
+	        "GCOO5YBOIFXELMXBW5QAXQXURTDBBLXZAWQE424DIAWOYEUXG3QTFMLK"
+	    ],
+	    "amount": "1",
+	    "count": 1,
+	    "elapsed": "2.1623947930",
+	    "error": null,
+	    "source": "GDNSUHR7G5LS6WTVHQJULOTEXXCYBPNK7NXB323VEBCEY7LEJWFEEXSN",
+	    "type": "payment"
+	}
+*/
+type RecordPayment struct {
+	BaseResultRecord
+	Addresses   []string       `json:"addresses"`
+	Count       uint64         `json:"count"`
+	Amount      common.Amount  `json:"amount"`
+	Source      string         `json:"source"`
+	Transaction string         `json:"transaction"`
+	Timings     *RecordTimings `json:"timings"`
+	// NOTE the block height, which `go` knew, when the transaction was
+	// submitted and the block height, which contains the transaction; 0 is
+	// unknown.
+	SubmitHeight    uint64 `json:"submit-height"`
+	InclusionHeight uint64 `json:"inclusion-height"`
+}
+
+// BlocksUntilInclusion returns how many blocks were made after the transaction
+// was submitted until the block containing it; 1 means the next block.
+func (r RecordPayment) BlocksUntilInclusion() (blocks int64, found bool) {
+	if r.SubmitHeight < 1 || r.InclusionHeight < 1 {
+		return
+	}
 
-func (r RecordCreateAccounts) GetType() string {
-	return r.Type
+	return int64(r.InclusionHeight) - int64(r.SubmitHeight), true
 }
 
-func (r RecordCreateAccounts) GetElapsed() int64 {
-	p, _ := ParseRecordElapsedTime(r.Elapsed)
-	return p
+// RecordTimings is the moments of payment from signing the transaction to
+// observing it in block; the `elapsed` of payment is from `post-ack` to
+// `confirmed`.
+type RecordTimings struct {
+	SignStart  string `json:"sign-start"`
+	PostSent   string `json:"post-sent"`
+	PostAck    string `json:"post-ack"`
+	FirstPoll  string `json:"first-poll"`
+	MissedPoll string `json:"missed-poll"` // NOTE the last poll, which did not find the transaction; `post-ack`, if the first poll found it
+	LastPoll   string `json:"last-poll"`   // NOTE the poll, which found the transaction
+	Confirmed  string `json:"confirmed"`   // NOTE empty, when not confirmed
 }
 
-func (r RecordCreateAccounts) GetRawError() map[string]interface{} {
-	return r.Error
+func parseTiming(s string) time.Time {
+	t, _ := ParseRecordTime(s)
+	return t
 }
 
-func (r RecordCreateAccounts) GetError() error {
-	if len(r.Error) < 1 {
-		return nil
+func between(a, b string) time.Duration {
+	if len(a) < 1 || len(b) < 1 {
+		return 0
 	}
 
-	return fmt.Errorf("%v", r.Error)
-}
-
-func (r RecordCreateAccounts) GetErrorType() RecordErrorType {
-	return ParseRecordError(r.Error)
-}
-
-/*
-{
-    "addresses": [
-        "GCOO5YBOIFXELMXBW5QAXQXURTDBBLXZAWQE424DIAWOYEUXG3QTFMLK"
-    ],
-    "amount": "1",
-    "count": 1,
-    "elapsed": "2.1623947930",
-    "error": null,
-    "source": "GDNSUHR7G5LS6WTVHQJULOTEXXCYBPNK7NXB323VEBCEY7LEJWFEEXSN",
-    "type": "payment"
-}
-*/
-type RecordPayment struct {
-	Time      string                 `json:"time"`
-	Type      string                 `json:"type"`
-	Addresses []string               `json:"addresses"`
-	Count     uint64                 `json:"count"`
-	Elapsed   string                 `json:"elapsed"`
-	Error     map[string]interface{} `json:"error"`
-	Amount    common.Amount          `json:"amount"`
-	Source    string                 `json:"source"`
-}
-
-func (r RecordPayment) GetTime() time.Time {
-	t, _ := common.ParseISO8601(r.Time)
-	return t
+	return parseTiming(b).Sub(parseTiming(a))
 }
 
-func (r RecordPayment) GetType() string {
-	return r.Type
+// Signing is the time to create and sign the transaction.
+func (r RecordTimings) Signing() time.Duration {
+	return between(r.SignStart, r.PostSent)
 }
 
-func (r RecordPayment) GetElapsed() int64 {
-	p, _ := ParseRecordElapsedTime(r.Elapsed)
-	return p
+// Submit is the time to post the transaction until the node accepts it,
+// including the retries.
+func (r RecordTimings) Submit() time.Duration {
+	return between(r.PostSent, r.PostAck)
 }
 
-func (r RecordPayment) GetRawError() map[string]interface{} {
-	return r.Error
+// Confirmation is the time from the accepted transaction to observing it in
+// block.
+func (r RecordTimings) Confirmation() time.Duration {
+	return between(r.PostAck, r.Confirmed)
 }
 
-func (r RecordPayment) GetError() error {
-	if len(r.Error) < 1 {
-		return nil
+// PollingOverhead is the time of confirmation, which can be spent by polling,
+// not by consensus; the window from the last poll, which did not find the
+// transaction, to confirmed. The transaction was stored in block somewhere in
+// this window, so it is the upper bound of time lost by the poll interval.
+func (r RecordTimings) PollingOverhead() time.Duration {
+	if len(r.Confirmed) < 1 {
+		return 0
 	}
 
-	return fmt.Errorf("%v", r.Error)
-}
+	// NOTE the old record does not have `missed-poll`
+	if len(r.MissedPoll) < 1 {
+		return between(r.PostAck, r.FirstPoll) + between(r.LastPoll, r.Confirmed)
+	}
 
-func (r RecordPayment) GetErrorType() RecordErrorType {
-	return ParseRecordError(r.Error)
+	return between(r.MissedPoll, r.Confirmed)
 }
 
 type RecordSEBAKError struct {
-	Time      string                 `json:"time"`
-	Type      string                 `json:"type"`
-	Addresses []string               `json:"addresses"`
-	Count     uint64                 `json:"count"`
-	Elapsed   string                 `json:"elapsed"`
-	Error     map[string]interface{} `json:"error"`
-	when      string                 `json:"when"`
-}
-
-func (r RecordSEBAKError) GetTime() time.Time {
-	t, _ := common.ParseISO8601(r.Time)
-	return t
+	BaseResultRecord
+	Addresses   []string      `json:"addresses"`
+	Count       uint64        `json:"count"`
+	Amount      common.Amount `json:"amount"`
+	Source      string        `json:"source"`
+	Transaction string        `json:"transaction"`
+	When        string        `json:"when"` // NOTE "create-account" or "payment"
 }
 
-func (r RecordSEBAKError) GetType() string {
-	return r.Type
+/*
+	{
+	    "action": "concurrency",
+	    "previous": 100,
+	    "time": "2018-11-04T16:37:35.275133000",
+	    "type": "control",
+	    "value": 200
+	}
+*/
+type RecordControl struct {
+	BaseRecord
+	Action   string      `json:"action"`
+	Value    interface{} `json:"value"`
+	Previous interface{} `json:"previous"`
 }
 
-func (r RecordSEBAKError) GetElapsed() int64 {
-	p, _ := ParseRecordElapsedTime(r.Elapsed)
-	return p
+/*
+{
+    "time": "2018-11-04T16:37:35.275133000",
+    "type": "started"
 }
-
-func (r RecordSEBAKError) GetRawError() map[string]interface{} {
-	return r.Error
+*/
+// RecordMark marks the moment of running, like `started` and `ended`.
+type RecordMark struct {
+	BaseRecord
 }
 
-func (r RecordSEBAKError) GetError() error {
-	if len(r.Error) < 1 {
-		return nil
-	}
-
-	return fmt.Errorf("%v", r.Error)
+/*
+{
+    "address": "GDPKGW3DPNSDZV6R5ESTTQ56IS34IDQQ2TEKZG5OBA6EXLH3NLTTSLDE",
+    "reason": "insufficient balance: balance=9990000 required=10020000",
+    "time": "2018-11-04T16:37:35.275133000",
+    "type": "account-stopped"
 }
-
-func (r RecordSEBAKError) GetErrorType() RecordErrorType {
-	return ParseRecordError(r.Error)
+*/
+// RecordAccountStopped is written when the account stops sending payments
+// before the end of testing, like insufficient balance.
+type RecordAccountStopped struct {
+	BaseRecord
+	Address string `json:"address"`
+	Reason  string `json:"reason"`
 }
@@ -0,0 +1,251 @@
+package hotbody
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+// ErrorCategory groups the errors by what went wrong; the SEBAK errors are
+// `validation`, `sequence`, `balance`, `pool`, `consensus` and `internal`,
+// the connection errors are `network`.
+type ErrorCategory string
+
+const (
+	ErrorCategoryValidation ErrorCategory = "validation"
+	ErrorCategorySequence   ErrorCategory = "sequence"
+	ErrorCategoryBalance    ErrorCategory = "balance"
+	ErrorCategoryPool       ErrorCategory = "pool"
+	ErrorCategoryConsensus  ErrorCategory = "consensus"
+	ErrorCategoryInternal   ErrorCategory = "internal"
+	ErrorCategoryNetwork    ErrorCategory = "network"
+	ErrorCategoryUnknown    ErrorCategory = "unknown"
+)
+
+var ErrorCategories = []ErrorCategory{
+	ErrorCategoryValidation,
+	ErrorCategorySequence,
+	ErrorCategoryBalance,
+	ErrorCategoryPool,
+	ErrorCategoryConsensus,
+	ErrorCategoryInternal,
+	ErrorCategoryNetwork,
+	ErrorCategoryUnknown,
+}
+
+func (c ErrorCategory) IsValid() bool {
+	for _, e := range ErrorCategories {
+		if c == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+const sebakProblemTypePrefix string = "https://boscoin.io/sebak/error/"
+
+// ErrorInfo explains the error; `Retryable` means the same transaction can
+// succeed, if it is sent again later.
+type ErrorInfo struct {
+	Code        int           `json:"code,omitempty"` // NOTE SEBAK error code
+	Type        string        `json:"type,omitempty"` // NOTE SEBAK problem type
+	Name        string        `json:"name"`
+	Category    ErrorCategory `json:"category"`
+	Retryable   bool          `json:"retryable"`
+	Description string        `json:"description,omitempty"`
+}
+
+// NOTE SEBAKErrorCatalogue has the errors of `boscoin.io/sebak/lib/errors`;
+// the code and description come from SEBAK. The SEBAK errors can be added or
+// overridden by LoadErrorCatalogue without rebuilding.
+var SEBAKErrorCatalogue = map[int]ErrorInfo{}
+
+type sebakErrorInfo struct {
+	err       *errors.Error
+	name      string
+	category  ErrorCategory
+	retryable bool
+}
+
+var sebakErrors = []sebakErrorInfo{
+	{errors.BlockAlreadyExists, "block-already-exists", ErrorCategoryConsensus, false},
+	{errors.BlockTransactionDoesNotExists, "transaction-not-found", ErrorCategoryConsensus, true},
+	{errors.BlockOperationDoesNotExists, "operation-not-found", ErrorCategoryConsensus, true},
+	{errors.NewButKnownMessage, "known-message", ErrorCategoryPool, false},
+	{errors.InvalidState, "invalid-state", ErrorCategoryConsensus, false},
+	{errors.InvalidVotingThresholdPolicy, "invalid-voting-threshold-policy", ErrorCategoryConsensus, false},
+	{errors.BallotEmptyMessage, "ballot-empty-message", ErrorCategoryConsensus, false},
+	{errors.BallotHasMessage, "ballot-has-message", ErrorCategoryConsensus, false},
+	{errors.VotingResultAlreadyExists, "voting-result-already-exists", ErrorCategoryConsensus, false},
+	{errors.VotingResultNotFound, "voting-result-not-found", ErrorCategoryConsensus, false},
+	{errors.VotingResultFailedToSetState, "voting-result-failed-to-set-state", ErrorCategoryConsensus, false},
+	{errors.VotingResultNotInBox, "voting-result-not-in-box", ErrorCategoryConsensus, false},
+	{errors.VotingResultFailedToClose, "voting-result-failed-to-close", ErrorCategoryConsensus, false},
+	{errors.BallotNoVoting, "ballot-no-voting", ErrorCategoryConsensus, false},
+	{errors.BallotNoNodeKey, "ballot-no-node-key", ErrorCategoryConsensus, false},
+	{errors.BallotHasInvalidState, "ballot-has-invalid-state", ErrorCategoryConsensus, false},
+	{errors.BallotFromUnknownValidator, "ballot-from-unknown-validator", ErrorCategoryConsensus, false},
+	{errors.BallotAlreadyFinished, "ballot-already-finished", ErrorCategoryConsensus, false},
+	{errors.BallotAlreadyVoted, "ballot-already-voted", ErrorCategoryConsensus, false},
+	{errors.VotingThresholdInvalidValidators, "invalid-validators", ErrorCategoryConsensus, false},
+	{errors.RoundVoteNotFound, "round-vote-not-found", ErrorCategoryConsensus, false},
+	{errors.StopConsensus, "stop-consensus", ErrorCategoryConsensus, true},
+	{errors.HashDoesNotMatch, "hash-does-not-match", ErrorCategoryValidation, false},
+	{errors.SignatureVerificationFailed, "signature-verification-failed", ErrorCategoryValidation, false},
+	{errors.BadPublicAddress, "bad-public-address", ErrorCategoryValidation, false},
+	{errors.InvalidFee, "invalid-fee", ErrorCategoryValidation, false},
+	{errors.InvalidOperation, "invalid-operation", ErrorCategoryValidation, false},
+	{errors.InvalidHash, "invalid-hash", ErrorCategoryValidation, false},
+	{errors.InvalidMessage, "invalid-message", ErrorCategoryValidation, false},
+	{errors.TransactionEmptyOperations, "empty-operations", ErrorCategoryValidation, false},
+	{errors.TransactionHasOverMaxOperations, "over-max-operations", ErrorCategoryValidation, false},
+	{errors.DuplicatedOperation, "duplicated-operation", ErrorCategoryValidation, false},
+	{errors.UnknownOperationType, "unknown-operation-type", ErrorCategoryValidation, false},
+	{errors.TypeOperationBodyNotMatched, "operation-body-not-matched", ErrorCategoryValidation, false},
+	{errors.OperationAmountUnderflow, "amount-underflow", ErrorCategoryValidation, false},
+	{errors.OperationAmountOverflow, "amount-overflow", ErrorCategoryValidation, false},
+	{errors.BlockAccountDoesNotExists, "account-not-found", ErrorCategoryValidation, false},
+	{errors.BlockAccountAlreadyExists, "account-already-exists", ErrorCategoryValidation, false},
+	{errors.InvalidProposerTransaction, "invalid-proposer-transaction", ErrorCategoryValidation, false},
+	{errors.HTTPProblem, "http-problem", ErrorCategoryValidation, false},
+	{errors.TransactionInvalidSequenceID, "invalid-sequence-id", ErrorCategorySequence, false},
+	{errors.AccountBalanceUnderZero, "balance-under-zero", ErrorCategoryBalance, false},
+	{errors.MaximumBalanceReached, "maximum-balance-reached", ErrorCategoryBalance, false},
+	{errors.TransactionExcessAbilityToPay, "excess-ability-to-pay", ErrorCategoryBalance, false},
+	{errors.InsufficientAmountNewAccount, "insufficient-amount-new-account", ErrorCategoryBalance, false},
+	{errors.TransactionSameSource, "same-source-in-pool", ErrorCategoryPool, true},
+	{errors.StorageRecordDoesNotExist, "storage-record-not-found", ErrorCategoryInternal, false},
+	{errors.AlreadySaved, "already-saved", ErrorCategoryInternal, false},
+	{errors.NotImplemented, "not-implemented", ErrorCategoryInternal, false},
+}
+
+func init() {
+	for _, e := range sebakErrors {
+		SEBAKErrorCatalogue[int(e.err.Code)] = ErrorInfo{
+			Name:        e.name,
+			Category:    e.category,
+			Retryable:   e.retryable,
+			Description: e.err.Message,
+		}
+	}
+}
+
+// ParseSEBAKProblemType returns the SEBAK error code from problem type, like
+// `https://boscoin.io/sebak/error/134`.
+func ParseSEBAKProblemType(t string) (code int, found bool) {
+	if !strings.Contains(t, "/sebak/error/") {
+		return
+	}
+
+	var err error
+	if code, err = strconv.Atoi(t[strings.LastIndex(t, "/")+1:]); err != nil {
+		return
+	}
+
+	return code, true
+}
+
+// LookupSEBAKError returns the ErrorInfo of SEBAK error code.
+func LookupSEBAKError(code int) (info ErrorInfo, found bool) {
+	if info, found = SEBAKErrorCatalogue[code]; !found {
+		return
+	}
+
+	info.Code = code
+	info.Type = fmt.Sprintf("%s%d", sebakProblemTypePrefix, code)
+
+	return
+}
+
+// LoadErrorCatalogue reads the JSON list of ErrorInfo and adds them to
+// SEBAKErrorCatalogue; each one must have `code` or `type`.
+func LoadErrorCatalogue(r io.Reader) (err error) {
+	var infos []ErrorInfo
+	if err = json.NewDecoder(r).Decode(&infos); err != nil {
+		return
+	}
+
+	for i, info := range infos {
+		if info.Code < 1 {
+			var found bool
+			if info.Code, found = ParseSEBAKProblemType(info.Type); !found {
+				return fmt.Errorf("%d: `code` or `type` is missing", i)
+			}
+		}
+		if len(info.Name) < 1 {
+			return fmt.Errorf("%d: `name` is missing", i)
+		}
+		if len(info.Category) < 1 {
+			info.Category = ErrorCategoryUnknown
+		}
+		if !info.Category.IsValid() {
+			return fmt.Errorf("%d: unknown category, '%s'", i, info.Category)
+		}
+
+		SEBAKErrorCatalogue[info.Code] = info
+	}
+
+	return
+}
+
+// nameFromTitle makes the name from the title of SEBAK problem,
+// `transaction does not exist` becomes `transaction-does-not-exist`.
+func nameFromTitle(title string) string {
+	f := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+
+	return strings.Join(f, "-")
+}
+
+// ExplainError returns the ErrorInfo of classified error. The SEBAK error,
+// which is not in SEBAKErrorCatalogue, is named by the title of problem and
+// it's category is `unknown`.
+func ExplainError(c RecordErrorClass) (info ErrorInfo) {
+	switch c.Class {
+	case ErrorClassSEBAK:
+		var found bool
+		if info, found = LookupSEBAKError(c.Code); found {
+			return
+		}
+
+		info = ErrorInfo{
+			Code:        c.Code,
+			Type:        fmt.Sprintf("%s%d", sebakProblemTypePrefix, c.Code),
+			Name:        nameFromTitle(c.Title),
+			Category:    ErrorCategoryUnknown,
+			Description: c.Title,
+		}
+		if len(info.Name) < 1 {
+			info.Name = c.Key()
+		}
+	case ErrorClassHTTPStatus:
+		info = ErrorInfo{Name: c.Key(), Category: ErrorCategoryValidation}
+		if c.Status >= 500 {
+			info.Category = ErrorCategoryInternal
+			info.Retryable = true
+		} else if c.Status == 429 {
+			info.Retryable = true
+		}
+	case ErrorClassReset, ErrorClassRefused, ErrorClassTimeout, ErrorClassEOF, ErrorClassNetwork:
+		info = ErrorInfo{Name: string(c.Class), Category: ErrorCategoryNetwork, Retryable: true}
+	case ErrorClassDNS, ErrorClassTLS:
+		info = ErrorInfo{Name: string(c.Class), Category: ErrorCategoryNetwork}
+	case ErrorClassConfirmTimeout:
+		info = ErrorInfo{
+			Name:        string(c.Class),
+			Category:    ErrorCategoryConsensus,
+			Retryable:   true,
+			Description: "transaction is not stored in block within confirm duration",
+		}
+	default:
+		info = ErrorInfo{Name: c.Key(), Category: ErrorCategoryUnknown}
+	}
+
+	return
+}
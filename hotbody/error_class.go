@@ -0,0 +1,280 @@
+package hotbody
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+// ErrorClass is the platform-independent kind of error; the errno of
+// syscall differs by platform, `ECONNRESET` is 54 in macOS and 104 in linux,
+// so the error is classified when it happens, not from the serialized error.
+type ErrorClass string
+
+const (
+	ErrorClassReset          ErrorClass = "reset"
+	ErrorClassRefused        ErrorClass = "refused"
+	ErrorClassTimeout        ErrorClass = "timeout"
+	ErrorClassDNS            ErrorClass = "dns"
+	ErrorClassTLS            ErrorClass = "tls"
+	ErrorClassEOF            ErrorClass = "eof"
+	ErrorClassHTTPStatus     ErrorClass = "http-status"
+	ErrorClassSEBAK          ErrorClass = "sebak"
+	ErrorClassConfirmTimeout ErrorClass = "confirm-timeout"
+	ErrorClassNetwork        ErrorClass = "network"
+	ErrorClassUnknown        ErrorClass = "unknown"
+)
+
+/*
+{
+    "class": "sebak",
+    "code": 134,
+    "message": "...",
+    "status": 404,
+    "title": "transaction does not exist"
+}
+*/
+// RecordErrorClass is the classified error, which is written in the record as
+// `error-class`.
+type RecordErrorClass struct {
+	Class   ErrorClass `json:"class"`
+	Status  int        `json:"status,omitempty"` // NOTE HTTP status
+	Code    int        `json:"code,omitempty"`   // NOTE SEBAK error code
+	Title   string     `json:"title,omitempty"`  // NOTE title of SEBAK problem
+	Message string     `json:"message"`
+}
+
+// Key returns the name of error type in the result; the SEBAK error has it's
+// code, like `sebak-134` and HTTP status error has it's status, like
+// `http-502`.
+func (c RecordErrorClass) Key() string {
+	switch c.Class {
+	case ErrorClassSEBAK:
+		return fmt.Sprintf("sebak-%d", c.Code)
+	case ErrorClassHTTPStatus:
+		return fmt.Sprintf("http-%d", c.Status)
+	case "":
+		return string(ErrorClassUnknown)
+	default:
+		return string(c.Class)
+	}
+}
+
+// IsKnownErrorType checks the error type of result, it is the class of error
+// or the SEBAK error code or HTTP status, like `sebak-134` and `http-502`.
+func IsKnownErrorType(t RecordErrorType) bool {
+	s := string(t)
+	for _, prefix := range []string{"sebak-", "http-"} {
+		if !strings.HasPrefix(s, prefix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(s[len(prefix):])
+		return err == nil && n > 0
+	}
+
+	switch ErrorClass(s) {
+	case ErrorClassReset, ErrorClassRefused, ErrorClassTimeout, ErrorClassDNS, ErrorClassTLS, ErrorClassEOF,
+		ErrorClassConfirmTimeout, ErrorClassNetwork, ErrorClassUnknown:
+		return true
+	}
+
+	return false
+}
+
+// ClassifiedError keeps the original error with it's class; it is serialized
+// same as the original error.
+type ClassifiedError struct {
+	Err   error
+	Class RecordErrorClass
+}
+
+func NewClassifiedError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*ClassifiedError); ok {
+		return err
+	}
+
+	return &ClassifiedError{Err: err, Class: ClassifyError(err)}
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Err)
+}
+
+// ErrorConfirmTimeout occurs when the sent transaction is not found in block
+// within `ConfirmDuration`.
+type ErrorConfirmTimeout struct {
+	Duration time.Duration `json:"duration"`
+}
+
+func (e *ErrorConfirmTimeout) Error() string {
+	return fmt.Sprintf("timeout: %v", e.Duration)
+}
+
+// ErrorClassOf returns the class of error; nil error returns nil.
+func ErrorClassOf(err error) *RecordErrorClass {
+	if err == nil {
+		return nil
+	}
+
+	if ce, ok := err.(*ClassifiedError); ok {
+		return &ce.Class
+	}
+
+	c := ClassifyError(err)
+	return &c
+}
+
+// ClassifyError follows the wrapped errors of `*url.Error`, `*net.OpError`
+// and `*os.SyscallError` and finds the class of error.
+func ClassifyError(err error) (c RecordErrorClass) {
+	c.Message = err.Error()
+	c.Class = ErrorClassUnknown
+
+	e := err
+	for e != nil {
+		switch t := e.(type) {
+		case *ClassifiedError:
+			return t.Class
+		case *errors.Error:
+			classifyHTTPProblem(t.Data, &c)
+			return
+		case *ErrorConfirmTimeout:
+			c.Class = ErrorClassConfirmTimeout
+			return
+		case *url.Error:
+			c.Class = ErrorClassNetwork
+			if t.Timeout() {
+				c.Class = ErrorClassTimeout
+				return
+			}
+			e = t.Err
+			continue
+		case *net.OpError:
+			c.Class = ErrorClassNetwork
+			if t.Timeout() {
+				c.Class = ErrorClassTimeout
+				return
+			}
+			e = t.Err
+			continue
+		case *os.SyscallError:
+			e = t.Err
+			continue
+		case syscall.Errno:
+			switch t {
+			case syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE:
+				c.Class = ErrorClassReset
+			case syscall.ECONNREFUSED:
+				c.Class = ErrorClassRefused
+			case syscall.ETIMEDOUT:
+				c.Class = ErrorClassTimeout
+			default:
+				c.Class = ErrorClassNetwork
+			}
+			return
+		case *net.DNSError:
+			c.Class = ErrorClassDNS
+			return
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError, tls.RecordHeaderError:
+			c.Class = ErrorClassTLS
+			return
+		}
+
+		break
+	}
+
+	switch {
+	case e == io.EOF || e == io.ErrUnexpectedEOF:
+		c.Class = ErrorClassEOF
+	case e == context.DeadlineExceeded:
+		c.Class = ErrorClassTimeout
+	default:
+		if ne, ok := e.(net.Error); ok && ne.Timeout() {
+			c.Class = ErrorClassTimeout
+			return
+		}
+
+		// NOTE some errors of tls and http2 are not exported, they can be
+		// found only by message.
+		msg := e.Error()
+		switch {
+		case strings.HasPrefix(msg, "tls: "), strings.HasPrefix(msg, "x509: "),
+			strings.Contains(msg, "server gave HTTP response to HTTPS client"):
+			c.Class = ErrorClassTLS
+		case strings.Contains(msg, "connection reset by peer"):
+			c.Class = ErrorClassReset
+		case strings.Contains(msg, "connection refused"):
+			c.Class = ErrorClassRefused
+		case strings.Contains(msg, "timed out"):
+			// NOTE ETIMEDOUT is "connection timed out" in linux and
+			// "operation timed out" in macOS
+			c.Class = ErrorClassTimeout
+		case strings.HasSuffix(msg, "EOF"):
+			c.Class = ErrorClassEOF
+		}
+	}
+
+	return
+}
+
+// classifyHTTPProblem classifies the non-200 response; if the body is the
+// SEBAK problem, the SEBAK error code is used.
+func classifyHTTPProblem(data map[string]interface{}, c *RecordErrorClass) {
+	c.Class = ErrorClassHTTPStatus
+
+	switch status := data["status"].(type) {
+	case int:
+		c.Status = status
+	case float64:
+		c.Status = int(status)
+	}
+
+	body, _ := data["body"].(string)
+	if code, title, found := parseSEBAKProblem(body); found {
+		c.Class = ErrorClassSEBAK
+		c.Code = code
+		c.Title = title
+	}
+}
+
+// parseSEBAKProblem finds the SEBAK error code from the problem body, `code`
+// or the last part of `type`, like `https://boscoin.io/sebak/error/134`.
+func parseSEBAKProblem(body string) (code int, title string, found bool) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &m); err != nil {
+		return
+	}
+
+	title, _ = m["title"].(string)
+
+	if v, ok := m["code"].(float64); ok {
+		return int(v), title, true
+	}
+
+	t, _ := m["type"].(string)
+	if code, found = ParseSEBAKProblemType(t); !found {
+		return
+	}
+
+	return code, title, true
+}
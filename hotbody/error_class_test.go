@@ -0,0 +1,70 @@
+package hotbody
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// wrapSyscallError wraps errno like the error of dialing or reading thru
+// http client.
+func wrapSyscallError(op, syscallName string, errno syscall.Errno) error {
+	return &url.Error{
+		Op:  "Post",
+		URL: "https://127.0.0.1:12345/api/v1/transactions",
+		Err: &net.OpError{Op: op, Net: "tcp", Err: os.NewSyscallError(syscallName, errno)},
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		// NOTE the errno is different by OS, like ECONNRESET is 104 in linux
+		// and 54 in macOS, but syscall has the value of running OS.
+		{name: "ECONNRESET", err: wrapSyscallError("read", "read", syscall.ECONNRESET), expected: ErrorClassReset},
+		{name: "ECONNABORTED", err: wrapSyscallError("read", "read", syscall.ECONNABORTED), expected: ErrorClassReset},
+		{name: "EPIPE", err: wrapSyscallError("write", "write", syscall.EPIPE), expected: ErrorClassReset},
+		{name: "ECONNREFUSED", err: wrapSyscallError("dial", "connect", syscall.ECONNREFUSED), expected: ErrorClassRefused},
+		{name: "ETIMEDOUT", err: wrapSyscallError("dial", "connect", syscall.ETIMEDOUT), expected: ErrorClassTimeout},
+		{name: "EHOSTUNREACH", err: wrapSyscallError("dial", "connect", syscall.EHOSTUNREACH), expected: ErrorClassNetwork},
+		{name: "bare errno", err: syscall.ECONNREFUSED, expected: ErrorClassRefused},
+
+		// NOTE the errors, which lost their type, like thru http2, are
+		// classified by the messages; the messages of reset and refused are
+		// same in linux and macOS, but timed out is not
+		{name: "reset message", err: errors.New("read tcp 127.0.0.1:50000->127.0.0.1:12345: read: connection reset by peer"), expected: ErrorClassReset},
+		{name: "refused message", err: errors.New("dial tcp 127.0.0.1:12345: connect: connection refused"), expected: ErrorClassRefused},
+		{name: "linux timed out message", err: errors.New("dial tcp 10.0.0.1:12345: connect: connection timed out"), expected: ErrorClassTimeout},
+		{name: "macos timed out message", err: errors.New("dial tcp 10.0.0.1:12345: connect: operation timed out"), expected: ErrorClassTimeout},
+
+		{name: "EOF", err: &url.Error{Op: "Get", URL: "https://127.0.0.1:12345", Err: io.EOF}, expected: ErrorClassEOF},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, expected: ErrorClassEOF},
+		{name: "EOF message", err: errors.New("http2: unexpected EOF"), expected: ErrorClassEOF},
+		{name: "deadline", err: context.DeadlineExceeded, expected: ErrorClassTimeout},
+		{name: "dns", err: &url.Error{Op: "Get", URL: "https://unknown:12345", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "unknown"}}}, expected: ErrorClassDNS},
+		{name: "tls message", err: errors.New("tls: first record does not look like a TLS handshake"), expected: ErrorClassTLS},
+		{name: "confirm timeout", err: &ErrorConfirmTimeout{Duration: time.Minute}, expected: ErrorClassConfirmTimeout},
+		{name: "classified", err: NewClassifiedError(wrapSyscallError("read", "read", syscall.ECONNRESET)), expected: ErrorClassReset},
+		{name: "unknown", err: fmt.Errorf("something wrong"), expected: ErrorClassUnknown},
+	}
+
+	for _, c := range cases {
+		got := ClassifyError(c.err)
+		if got.Class != c.expected {
+			t.Errorf("%s: expected %s, but %s; %v", c.name, c.expected, got.Class, c.err)
+		}
+		if len(got.Message) < 1 {
+			t.Errorf("%s: empty message", c.name)
+		}
+	}
+}
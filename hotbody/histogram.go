@@ -0,0 +1,257 @@
+package hotbody
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const DefaultHistogramSignificantFigures int = 3
+
+// Histogram is the high dynamic range histogram of nanosecond values. The
+// values are kept in the logarithmic buckets, which have linear sub-buckets,
+// so the recorded value can be restored within the given significant
+// figures, regardless of the magnitude.
+type Histogram struct {
+	subBucketHalfCountMagnitude uint
+	subBucketCount              int64
+	subBucketHalfCount          int64
+	subBucketMask               int64
+	counts                      []int64
+	totalCount                  int64
+	min                         int64
+	max                         int64
+	sum                         float64
+	sumSquares                  float64
+}
+
+func NewHistogram(significantFigures int) *Histogram {
+	if significantFigures < 1 || significantFigures > 5 {
+		significantFigures = DefaultHistogramSignificantFigures
+	}
+
+	largestValueWithSingleUnitResolution := 2 * math.Pow10(significantFigures)
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(largestValueWithSingleUnitResolution)))
+
+	h := &Histogram{
+		subBucketHalfCountMagnitude: subBucketCountMagnitude - 1,
+		subBucketCount:              1 << subBucketCountMagnitude,
+		min:                         -1,
+	}
+	h.subBucketHalfCount = h.subBucketCount / 2
+	h.subBucketMask = h.subBucketCount - 1
+
+	return h
+}
+
+func (h *Histogram) bucketIndex(v int64) int {
+	return bits.Len64(uint64(v|h.subBucketMask)) - int(h.subBucketHalfCountMagnitude+1)
+}
+
+func (h *Histogram) countsIndex(v int64) int {
+	bucketIndex := h.bucketIndex(v)
+	subBucketIndex := v >> uint(bucketIndex)
+
+	return ((bucketIndex + 1) << h.subBucketHalfCountMagnitude) + int(subBucketIndex-h.subBucketHalfCount)
+}
+
+func (h *Histogram) valueFromIndex(i int) int64 {
+	bucketIndex := (i >> h.subBucketHalfCountMagnitude) - 1
+	subBucketIndex := int64(i&int(h.subBucketHalfCount-1)) + h.subBucketHalfCount
+	if bucketIndex < 0 {
+		subBucketIndex -= h.subBucketHalfCount
+		bucketIndex = 0
+	}
+
+	return subBucketIndex << uint(bucketIndex)
+}
+
+func (h *Histogram) highestEquivalentValue(v int64) int64 {
+	bucketIndex := h.bucketIndex(v)
+	subBucketIndex := v >> uint(bucketIndex)
+	if subBucketIndex >= h.subBucketCount {
+		bucketIndex++
+	}
+	size := int64(1) << uint(bucketIndex)
+	lowest := (v >> uint(bucketIndex)) << uint(bucketIndex)
+
+	return lowest + size - 1
+}
+
+// Record records the value; the negative value is recorded as 0.
+func (h *Histogram) Record(v int64) {
+	if v < 0 {
+		v = 0
+	}
+
+	i := h.countsIndex(v)
+	if i >= len(h.counts) {
+		counts := make([]int64, i+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[i]++
+
+	h.totalCount++
+	h.sum += float64(v)
+	h.sumSquares += float64(v) * float64(v)
+	if h.min < 0 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+}
+
+func (h *Histogram) RecordDuration(d time.Duration) {
+	h.Record(d.Nanoseconds())
+}
+
+// Merge adds the all the values of other histogram.
+func (h *Histogram) Merge(o *Histogram) {
+	for i, c := range o.counts {
+		if c < 1 {
+			continue
+		}
+		if i >= len(h.counts) {
+			counts := make([]int64, len(o.counts))
+			copy(counts, h.counts)
+			h.counts = counts
+		}
+		h.counts[i] += c
+	}
+
+	if o.totalCount < 1 {
+		return
+	}
+
+	h.totalCount += o.totalCount
+	h.sum += o.sum
+	h.sumSquares += o.sumSquares
+	if h.min < 0 || o.min < h.min {
+		h.min = o.min
+	}
+	if o.max > h.max {
+		h.max = o.max
+	}
+}
+
+func (h *Histogram) Count() int64 {
+	return h.totalCount
+}
+
+func (h *Histogram) Min() int64 {
+	if h.min < 0 {
+		return 0
+	}
+
+	return h.min
+}
+
+func (h *Histogram) Max() int64 {
+	return h.max
+}
+
+func (h *Histogram) Mean() float64 {
+	if h.totalCount < 1 {
+		return 0
+	}
+
+	return h.sum / float64(h.totalCount)
+}
+
+func (h *Histogram) StdDev() float64 {
+	if h.totalCount < 1 {
+		return 0
+	}
+
+	mean := h.Mean()
+	variance := h.sumSquares/float64(h.totalCount) - mean*mean
+	if variance < 0 {
+		return 0
+	}
+
+	return math.Sqrt(variance)
+}
+
+// ValueAtPercentile returns the value, which the given percent of the
+// recorded values are less than or equal to.
+func (h *Histogram) ValueAtPercentile(p float64) int64 {
+	if h.totalCount < 1 {
+		return 0
+	}
+
+	if p > 100 {
+		p = 100
+	}
+
+	target := int64(p/100*float64(h.totalCount) + 0.5)
+	if target < 1 {
+		target = 1
+	}
+
+	var total int64
+	for i, c := range h.counts {
+		total += c
+		if total >= target {
+			v := h.highestEquivalentValue(h.valueFromIndex(i))
+			if v > h.max {
+				return h.max
+			}
+			if v < h.Min() {
+				return h.Min()
+			}
+			return v
+		}
+	}
+
+	return h.max
+}
+
+// CountBetween returns the number of values in between low(inclusive) and
+// high(exclusive).
+func (h *Histogram) CountBetween(low, high int64) int64 {
+	var count int64
+	for i, c := range h.counts {
+		if c < 1 {
+			continue
+		}
+
+		v := h.valueFromIndex(i)
+		if v >= low && v < high {
+			count += c
+		}
+	}
+
+	return count
+}
+
+var bucketWidths = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// AutoBucketWidth chooses the readable bucket width for the distribution of
+// values from 0 to max within the given number of buckets.
+func AutoBucketWidth(max int64, buckets int) time.Duration {
+	for _, w := range bucketWidths {
+		if max/int64(w) < int64(buckets) {
+			return w
+		}
+	}
+
+	return time.Duration(max/int64(buckets)) + 1
+}
@@ -0,0 +1,105 @@
+package hotbody
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogramPercentiles(t *testing.T) {
+	cases := []struct {
+		name       string
+		values     []int64
+		percentile float64
+		expected   int64
+	}{
+		{name: "empty", values: nil, percentile: 99, expected: 0},
+		{name: "single", values: []int64{7}, percentile: 50, expected: 7},
+		{name: "p0 is min", values: sequence(1, 100, 1), percentile: 0, expected: 1},
+		{name: "p50", values: sequence(1, 100, 1), percentile: 50, expected: 50},
+		{name: "p90", values: sequence(1, 100, 1), percentile: 90, expected: 90},
+		{name: "p99", values: sequence(1, 100, 1), percentile: 99, expected: 99},
+		{name: "p100 is max", values: sequence(1, 100, 1), percentile: 100, expected: 100},
+		{name: "over 100", values: sequence(1, 100, 1), percentile: 120, expected: 100},
+		{
+			name:       "seconds p50",
+			values:     sequence(int64(time.Second), int64(100*time.Second), int64(time.Second)),
+			percentile: 50,
+			expected:   int64(50 * time.Second),
+		},
+		{
+			name:       "seconds p99",
+			values:     sequence(int64(time.Second), int64(100*time.Second), int64(time.Second)),
+			percentile: 99,
+			expected:   int64(99 * time.Second),
+		},
+		{name: "negative is 0", values: []int64{-5, 10}, percentile: 50, expected: 0},
+	}
+
+	for _, c := range cases {
+		h := NewHistogram(DefaultHistogramSignificantFigures)
+		for _, v := range c.values {
+			h.Record(v)
+		}
+
+		// NOTE the value is restored within the significant figures
+		got := h.ValueAtPercentile(c.percentile)
+		if diff := math.Abs(float64(got - c.expected)); diff > float64(c.expected)/1000 {
+			t.Errorf("%s: expected %d, but %d", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestHistogramStatistics(t *testing.T) {
+	h := NewHistogram(DefaultHistogramSignificantFigures)
+	for _, v := range sequence(1, 100, 1) {
+		h.Record(v)
+	}
+
+	if h.Count() != 100 {
+		t.Errorf("count: expected 100, but %d", h.Count())
+	}
+	if h.Min() != 1 {
+		t.Errorf("min: expected 1, but %d", h.Min())
+	}
+	if h.Max() != 100 {
+		t.Errorf("max: expected 100, but %d", h.Max())
+	}
+	if h.Mean() != 50.5 {
+		t.Errorf("mean: expected 50.5, but %v", h.Mean())
+	}
+	if d := math.Abs(h.StdDev() - 28.866); d > 0.001 {
+		t.Errorf("stddev: expected 28.866, but %v", h.StdDev())
+	}
+	if c := h.CountBetween(10, 20); c != 10 {
+		t.Errorf("count between 10 and 20: expected 10, but %d", c)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a := NewHistogram(DefaultHistogramSignificantFigures)
+	for _, v := range sequence(1, 50, 1) {
+		a.Record(v)
+	}
+	b := NewHistogram(DefaultHistogramSignificantFigures)
+	for _, v := range sequence(51, 100, 1) {
+		b.Record(v)
+	}
+	a.Merge(b)
+	a.Merge(NewHistogram(DefaultHistogramSignificantFigures))
+
+	if a.Count() != 100 || a.Min() != 1 || a.Max() != 100 {
+		t.Errorf("expected 100 values from 1 to 100, but %d values from %d to %d", a.Count(), a.Min(), a.Max())
+	}
+	if p := a.ValueAtPercentile(99); p != 99 {
+		t.Errorf("p99: expected 99, but %d", p)
+	}
+}
+
+func sequence(from, to, step int64) (values []int64) {
+	for v := from; v <= to; v += step {
+		values = append(values, v)
+	}
+
+	return
+}
@@ -1,9 +1,9 @@
 package hotbody
 
 import (
+	"context"
 	"fmt"
 	"math"
-	"math/rand"
 	"net"
 	"net/url"
 	"os"
@@ -46,6 +46,8 @@ type HotterConfig struct {
 	ConfirmDuration time.Duration `json:"confirm-duration"`
 	ResultOutput    string        `json:"result-output"`
 	Operations      int           `json:"operations"`
+	Seed            int64         `json:"seed"`
+	Replay          *ReplayConfig `json:"replay,omitempty"`
 }
 
 func (r HotterConfig) GetTime() time.Time {
@@ -72,6 +74,10 @@ func (r HotterConfig) GetErrorType() RecordErrorType {
 	return RecordErrorUnknown
 }
 
+func (r HotterConfig) GetErrorClass() RecordErrorClass {
+	return RecordErrorClass{}
+}
+
 func (r HotterConfig) Serialize() ([]byte, error) {
 	return common.JSONMarshalIndent(r)
 }
@@ -80,26 +86,61 @@ type Hotter struct {
 	sync.RWMutex
 	HotterConfig
 
+	controlLock     sync.Mutex // NOTE the controls are applied one by one
 	result          *Result
 	clients         []*HTTP2Client
 	keys            map[string]*keypair.Full
+	numberOfKeys    int
+	rands           map[string]*SeededRand // NOTE SeededRand of testing accounts and the other purposes
 	createdAccounts []string
 	runningAccounts *RunningAccounts
 	cachedAddresses map[string][]string
 	run             chan string
+	started         time.Time
+	blockHeight     uint64
+	blockHeights    map[string]uint64 // NOTE cached block height by hash
+	deadline        time.Time
+	deadlineChanged chan bool
+	paused          bool
+	rate            int
+	lastDispatched  time.Time
+	inflight        int
+	idle            []string
+}
+
+type HotterStatus struct {
+	Started     time.Time         `json:"started"`
+	Timeout     time.Duration     `json:"timeout"`
+	T           int               `json:"t"`
+	Running     int               `json:"running"`
+	Operations  int               `json:"operations"`
+	Paused      bool              `json:"paused"`
+	Rate        int               `json:"rate"`
+	Idle        int               `json:"idle"`
+	BlockHeight uint64            `json:"block-height"`
+	Endpoints   []HTTP2ClientStat `json:"endpoints"`
 }
 
 func NewHotter(
 	config HotterConfig,
 	clients []*HTTP2Client,
 ) (hotter *Hotter, err error) {
+	// NOTE without seed, the new seed is given and it is recorded in `config`,
+	// so the running can be reproduced.
+	if config.Seed == 0 {
+		config.Seed = NewSeed()
+	}
+
 	hotter = &Hotter{
 		HotterConfig: config,
 		clients:      clients,
 		keys: map[string]*keypair.Full{
 			config.KP.Address(): config.KP,
 		},
-		run: make(chan string),
+		rands:           map[string]*SeededRand{},
+		run:             make(chan string),
+		deadlineChanged: make(chan bool, 1),
+		blockHeights:    map[string]uint64{},
 	}
 
 	hotter.result, err = NewResult(config)
@@ -107,12 +148,50 @@ func NewHotter(
 	return
 }
 
+func (h *Hotter) Result() *Result {
+	return h.result
+}
+
+// Status returns the current state of running hotter.
+func (h *Hotter) Status() HotterStatus {
+	h.RLock()
+	defer h.RUnlock()
+
+	status := HotterStatus{
+		Started:     h.started,
+		Timeout:     h.Timeout,
+		T:           h.T,
+		Operations:  h.Operations,
+		Paused:      h.paused,
+		Rate:        h.rate,
+		Idle:        len(h.idle),
+		BlockHeight: h.blockHeight,
+	}
+	if h.runningAccounts != nil {
+		status.Running = h.runningAccounts.Len()
+	}
+	for _, client := range h.clients {
+		status.Endpoints = append(status.Endpoints, client.Stat())
+	}
+
+	return status
+}
+
 func (h *Hotter) Start() (err error) {
+	return h.StartContext(context.Background())
+}
+
+// StartContext starts like Start, but it can be stopped by ctx; while
+// creating accounts, it stops with the error of ctx and after started, it is
+// ended like End().
+func (h *Hotter) StartContext(ctx context.Context) (err error) {
 	log.Debug("hotter started")
 
 	var initAccount BlockAccount
 	if initAccount, err = h.GetAccount(h.KP.Address(), false); err != nil {
 		return
+	} else if err = ctx.Err(); err != nil {
+		return
 	}
 
 	log.Debug("init account found", "account", initAccount)
@@ -122,42 +201,12 @@ func (h *Hotter) Start() (err error) {
 	}
 
 	numberOfAccounts := int(math.Max(float64(h.T), float64(h.Operations))) + 1
-
-	n := numberOfAccounts / h.Node.Policy.OperationsLimit
-	if numberOfAccounts%h.Node.Policy.OperationsLimit > 0 {
-		n += 1
-	}
-	for i := 0; i < n; i++ {
-		l := h.Node.Policy.OperationsLimit
-		if (i+1)*h.Node.Policy.OperationsLimit > numberOfAccounts {
-			l = numberOfAccounts % h.Node.Policy.OperationsLimit
-		}
-
-		var targets []string
-		for j := 0; j < l; j++ {
-			k := h.NewKeypair()
-			targets = append(targets, k.Address())
-		}
-		if err = h.createAccounts(h.KP, h.Node.Policy.BaseReserve*100, targets...); err != nil {
-			return
-		}
-		h.createdAccounts = append(h.createdAccounts, targets...)
-		log.Debug("created accounts", "count", len(targets))
+	if _, err = h.prepareAccounts(ctx, numberOfAccounts); err != nil {
+		return
 	}
 
 	h.runningAccounts = &RunningAccounts{}
 
-	log.Debug("cached accounts")
-	h.cachedAddresses = map[string][]string{}
-	for _, address := range h.createdAccounts {
-		for _, otherAddress := range h.createdAccounts {
-			if address == otherAddress {
-				continue
-			}
-			h.cachedAddresses[address] = append(h.cachedAddresses[address], otherAddress)
-		}
-	}
-
 	log.Debug("created all accounts", "count", len(h.keys)-1)
 
 	log.Debug("start to make SEBAK to be hotter and hotter")
@@ -174,32 +223,15 @@ func (h *Hotter) Start() (err error) {
 				if startStop {
 					return
 				}
-				go func(a string) {
-					if h.runningAccounts.IsActive(address) {
-						return
-					}
 
-					h.runningAccounts.SetActive(a)
-					defer func(b string) {
-						h.runningAccounts.SetDeactive(b)
-					}(a)
-
-					log_ := log.New(logging.Ctx{"m": "request", "address": A(a)})
-					log_.Debug("start request", "running", h.runningAccounts.Len())
-
-					if err := h.request(a); err != nil {
-						if _, ok := err.(*ErrorStopRunning); ok {
-							log_.Debug("stop request", "address", a, "reason", err)
-							return
-						}
-						log_.Error("request failed", "address", a, "error", err)
-					}
-					log_.Debug("end", "running", h.runningAccounts.Len())
+				// NOTE when paused or the concurrency is full, the account
+				// waits in idle until it is dispatched again.
+				if !h.acquire() {
+					h.park(address)
+					continue
+				}
 
-					go func() {
-						h.run <- a
-					}()
-				}(address)
+				go h.work(address)
 			}
 		}
 	}()
@@ -216,17 +248,45 @@ func (h *Hotter) Start() (err error) {
 		}
 	}()
 
+	watchStopChan := make(chan bool)
+	go h.watchBlock(watchStopChan)
+
+	h.Lock()
+	h.started = time.Now()
+	h.deadline = h.started.Add(h.Timeout)
+	h.idle = append(h.idle, h.createdAccounts[h.T:]...)
+	dispatched := h.createdAccounts[:h.T]
+	h.Unlock()
+
 	h.result.Write("started")
-	for _, address := range h.createdAccounts[:h.T] {
+	for _, address := range dispatched {
 		h.run <- address
 	}
 
-	select {
-	case <-time.After(h.Timeout):
-		log.Debug("will be stopped; waiting for the existing requests closing", "timeout", h.Timeout)
+	done := ctx.Done()
+	for {
+		h.RLock()
+		remaining := time.Until(h.deadline)
+		h.RUnlock()
+
+		if remaining <= 0 {
+			break
+		}
 
-		stopChan <- true
+		select {
+		case <-time.After(remaining):
+		case <-h.deadlineChanged:
+		case <-done:
+			log.Debug("context canceled; trying to end", "error", ctx.Err())
+			done = nil
+			h.End()
+		}
 	}
+	h.RLock()
+	log.Debug("will be stopped; waiting for the existing requests closing", "timeout", h.Timeout)
+	h.RUnlock()
+
+	stopChan <- true
 
 	for {
 		if h.runningAccounts.Len() != 0 {
@@ -241,27 +301,172 @@ func (h *Hotter) Start() (err error) {
 
 	//close(h.run)
 	close(stopChan)
+	close(watchStopChan)
 	h.result.Close()
 
 	return
 }
 
-func (h *Hotter) Client() *HTTP2Client {
-	return h.clients[rand.Intn(3)%len(h.clients)]
+// work sends the requests of the account in the acquired slot of
+// concurrency. The slot is released before the account is dispatched again,
+// so the dispatcher always finds the free slot for it.
+func (h *Hotter) work(address string) {
+	// NOTE the account already running is dispatched again later.
+	if h.runningAccounts.IsActive(address) {
+		h.release()
+		h.park(address)
+		return
+	}
+
+	h.runningAccounts.SetActive(address)
+	h.waitRate()
+
+	log_ := log.New(logging.Ctx{"m": "request", "address": A(address)})
+	log_.Debug("start request", "running", h.runningAccounts.Len())
+
+	err := h.request(address)
+
+	h.runningAccounts.SetDeactive(address)
+	h.release()
+
+	if err != nil {
+		if _, ok := err.(*ErrorStopRunning); ok {
+			log_.Debug("stop request", "address", address, "reason", err)
+			h.result.Write("account-stopped", "address", address, "reason", err.Error())
+			h.dispatchIdle()
+			return
+		}
+		log_.Error("request failed", "address", address, "error", err)
+	}
+	log_.Debug("end", "running", h.runningAccounts.Len())
+
+	h.dispatchIdle()
+	go func() {
+		h.run <- address
+	}()
 }
 
-func (h *Hotter) NewKeypair() *keypair.Full {
-	k, _ := keypair.Random()
+// prepareAccounts creates new accounts from the init account and adds them
+// to the testing accounts.
+func (h *Hotter) prepareAccounts(ctx context.Context, numberOfAccounts int) (created []string, err error) {
+	n := numberOfAccounts / h.Node.Policy.OperationsLimit
+	if numberOfAccounts%h.Node.Policy.OperationsLimit > 0 {
+		n += 1
+	}
+	for i := 0; i < n; i++ {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		l := h.Node.Policy.OperationsLimit
+		if (i+1)*h.Node.Policy.OperationsLimit > numberOfAccounts {
+			l = numberOfAccounts % h.Node.Policy.OperationsLimit
+		}
+
+		var targets []string
+		for j := 0; j < l; j++ {
+			k := h.NewKeypair()
+			targets = append(targets, k.Address())
+		}
+		if err = h.createAccounts(ctx, h.KP, h.Node.Policy.BaseReserve*100, targets...); err != nil {
+			return
+		}
+		created = append(created, targets...)
+		log.Debug("created accounts", "count", len(targets))
+	}
 
 	h.Lock()
 	defer h.Unlock()
 
+	h.createdAccounts = append(h.createdAccounts, created...)
+
+	log.Debug("cached accounts")
+	h.cachedAddresses = map[string][]string{}
+	for _, address := range h.createdAccounts {
+		for _, otherAddress := range h.createdAccounts {
+			if address == otherAddress {
+				continue
+			}
+			h.cachedAddresses[address] = append(h.cachedAddresses[address], otherAddress)
+		}
+	}
+
+	return
+}
+
+// watchBlockRandName is the name of random generator for watchBlock.
+const watchBlockRandName string = "watch-block"
+
+// watchBlock keeps the latest block height of node until stopChan is closed.
+func (h *Hotter) watchBlock(stopChan chan bool) {
+	for {
+		if nodeInfo, err := h.GetNodeInfo(); err == nil {
+			h.Lock()
+			h.blockHeight = nodeInfo.Block.Height
+			h.Unlock()
+		}
+
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(1 * time.Second):
+		}
+	}
+}
+
+// Client returns the client of the endpoint chosen by the random generator of
+// name, the address of account or the purpose like watchBlockRandName, so the
+// endpoints are chosen in the same order with the same seed. The generator
+// of init account or purpose is derived from the seed and name at the first
+// use; they are not shared between goroutines.
+func (h *Hotter) Client(name string) *HTTP2Client {
+	h.RLock()
+	r, found := h.rands[name]
+	h.RUnlock()
+
+	if !found {
+		h.Lock()
+		if r, found = h.rands[name]; !found {
+			r = NewSeededRand(DeriveSeed(h.Seed, name))
+			h.rands[name] = r
+		}
+		h.Unlock()
+	}
+
+	return h.clients[r.Intn(len(h.clients))]
+}
+
+func (h *Hotter) NewKeypair() *keypair.Full {
+	h.Lock()
+	defer h.Unlock()
+
+	k, _ := DeriveKeypair(h.Seed, h.KP.Address(), h.numberOfKeys)
+	h.numberOfKeys++
+
 	h.keys[k.Address()] = k
+	h.rands[k.Address()] = NewSeededRand(DeriveSeed(h.Seed, k.Address()))
 
 	return k
 }
 
+func (h *Hotter) GetNodeInfo() (nodeInfo node.NodeInfo, err error) {
+	return h.getNodeInfo(h.Client(watchBlockRandName))
+}
+
+func (h *Hotter) getNodeInfo(client *HTTP2Client) (nodeInfo node.NodeInfo, err error) {
+	var b []byte
+	if b, err = client.Get("/", nil); err != nil {
+		return
+	}
+
+	return node.NewNodeInfoFromJSON(b)
+}
+
 func (h *Hotter) GetAccount(address string, ignoreLog bool) (ac BlockAccount, err error) {
+	return h.getAccount(h.Client(address), address, ignoreLog)
+}
+
+func (h *Hotter) getAccount(client *HTTP2Client, address string, ignoreLog bool) (ac BlockAccount, err error) {
 	var log_ logging.Logger
 	if ignoreLog {
 		log_ = nullLogger
@@ -274,7 +479,7 @@ func (h *Hotter) GetAccount(address string, ignoreLog bool) (ac BlockAccount, er
 
 	var b []byte
 	for i := 0; i < 3; i++ {
-		if b, err = h.Client().Get(url, nil); err != nil {
+		if b, err = client.Get(url, nil); err != nil {
 			if !ignoreLog {
 				log_.Error("failed", "error", err)
 			}
@@ -297,7 +502,7 @@ func (h *Hotter) GetAccount(address string, ignoreLog bool) (ac BlockAccount, er
 	return
 }
 
-func (h *Hotter) GetTransaction(hash string, ignoreLog bool) (ctx Transaction, err error) {
+func (h *Hotter) GetTransaction(client *HTTP2Client, hash string, ignoreLog bool) (ctx Transaction, err error) {
 	var log_ logging.Logger
 	if ignoreLog {
 		log_ = nullLogger
@@ -309,10 +514,11 @@ func (h *Hotter) GetTransaction(hash string, ignoreLog bool) (ctx Transaction, e
 	log_.Debug("starting", "url", url)
 
 	var b []byte
-	if b, err = h.Client().Get(url, nil); err != nil {
+	if b, err = client.Get(url, nil); err != nil {
 		if !ignoreLog {
 			log_.Error("failed", "error", err)
 		}
+		err = NewClassifiedError(err)
 		return
 	}
 
@@ -325,7 +531,36 @@ func (h *Hotter) GetTransaction(hash string, ignoreLog bool) (ctx Transaction, e
 	return
 }
 
-func (h *Hotter) createAccounts(sourceKP *keypair.Full, amount common.Amount, targets ...string) (err error) {
+// GetBlockHeight returns the height of block by hash; the height is cached,
+// because many transactions are in the same block.
+func (h *Hotter) GetBlockHeight(client *HTTP2Client, hash string) (height uint64, err error) {
+	h.RLock()
+	height, found := h.blockHeights[hash]
+	h.RUnlock()
+	if found {
+		return
+	}
+
+	var b []byte
+	if b, err = client.Get(fmt.Sprintf("%s/%s/blocks/%s", network.UrlPathPrefixAPI, api.APIVersionV1, hash), nil); err != nil {
+		err = NewClassifiedError(err)
+		return
+	}
+
+	var block Block
+	if block, err = NewBlockFromJSON(b); err != nil {
+		return
+	}
+	height = block.Height
+
+	h.Lock()
+	h.blockHeights[hash] = height
+	h.Unlock()
+
+	return
+}
+
+func (h *Hotter) createAccounts(ctx context.Context, sourceKP *keypair.Full, amount common.Amount, targets ...string) (err error) {
 	log_ := log.New(logging.Ctx{
 		"m":   "create-accounts",
 		"uid": common.GenerateUUID(),
@@ -351,8 +586,10 @@ func (h *Hotter) createAccounts(sourceKP *keypair.Full, amount common.Amount, ta
 		return
 	}
 
+	client := h.Client(sourceKP.Address())
+
 	var ac BlockAccount
-	if ac, err = h.GetAccount(sourceKP.Address(), true); err != nil {
+	if ac, err = h.getAccount(client, sourceKP.Address(), true); err != nil {
 		log_.Error(err.Error())
 		return
 	}
@@ -376,7 +613,7 @@ func (h *Hotter) createAccounts(sourceKP *keypair.Full, amount common.Amount, ta
 	tx.Sign(sourceKP, []byte(h.Node.Policy.NetworkID))
 	log_.Debug("transaction created", "transaction", tx.GetHash())
 
-	if err = h.sendTransaction(tx); err != nil {
+	if err = h.sendTransaction(client, tx); err != nil {
 		log_.Error("failed to send transaction", "error", err)
 
 		h.result.Write(
@@ -388,6 +625,7 @@ func (h *Hotter) createAccounts(sourceKP *keypair.Full, amount common.Amount, ta
 			"source", sourceKP.Address(),
 			"transaction", tx.GetHash(),
 			"error", err,
+			"error-class", ErrorClassOf(err),
 		)
 
 		return
@@ -401,28 +639,35 @@ func (h *Hotter) createAccounts(sourceKP *keypair.Full, amount common.Amount, ta
 			"addresses", targets,
 			"transaction", tx.GetHash(),
 			"error", err,
+			"error-class", ErrorClassOf(err),
 		)
 	}(time.Now(), log_)
 
 	// check transaction is stored in block
-	var ctx Transaction
+	var confirmed Transaction
 	for {
-		if ctx, err = h.GetTransaction(tx.GetHash(), true); err == nil {
+		if confirmed, err = h.GetTransaction(client, tx.GetHash(), true); err == nil {
 			break
 		}
 		err = nil
-		time.Sleep(time.Duration(600) * time.Millisecond)
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(time.Duration(600) * time.Millisecond):
+		}
 	}
 
 	log_.Debug(
 		"transaction confirmed",
-		"confirmed transaction", ctx,
+		"confirmed transaction", confirmed,
 	)
 
 	return
 }
 
-func (h *Hotter) payment(sourceKP *keypair.Full, amount common.Amount, targets ...string) (err error) {
+func (h *Hotter) payment(client *HTTP2Client, sourceKP *keypair.Full, amount common.Amount, targets ...string) (err error) {
 	log_ := log.New(logging.Ctx{"m": "payment", "uid": common.GenerateUUID()})
 
 	defer func(l logging.Logger) {
@@ -445,12 +690,14 @@ func (h *Hotter) payment(sourceKP *keypair.Full, amount common.Amount, targets .
 	}
 
 	var ac BlockAccount
-	if ac, err = h.GetAccount(sourceKP.Address(), true); err != nil {
+	if ac, err = h.getAccount(client, sourceKP.Address(), true); err != nil {
 		log_.Error(err.Error())
 		return
 	}
 	sequenceID := ac.SequenceID
 
+	timings := &RecordTimings{SignStart: FormatRecordTime(time.Now())}
+
 	var ops []operation.Operation
 	for _, target := range targets {
 		op, _ := operation.NewOperation(operation.Payment{
@@ -469,7 +716,20 @@ func (h *Hotter) payment(sourceKP *keypair.Full, amount common.Amount, targets .
 	tx.Sign(sourceKP, []byte(h.Node.Policy.NetworkID))
 	log_.Debug("transaction created", "transaction", tx.GetHash())
 
-	if err = h.sendTransaction(tx); err != nil {
+	// NOTE the block height of watchBlock() can be behind of the latest block
+	// up to a second, so the latest block height is fetched just before
+	// submitting; if failed, the blocks until inclusion is not counted.
+	var submitHeight uint64
+	if nodeInfo, err := h.getNodeInfo(client); err != nil {
+		log_.Error("failed to get block height", "error", err)
+	} else {
+		submitHeight = nodeInfo.Block.Height
+	}
+
+	var inclusionHeight uint64
+
+	timings.PostSent = FormatRecordTime(time.Now())
+	if err = h.sendTransaction(client, tx); err != nil {
 		log_.Error("failed to send transaction", "error", err)
 
 		h.result.Write(
@@ -481,46 +741,90 @@ func (h *Hotter) payment(sourceKP *keypair.Full, amount common.Amount, targets .
 			"source", sourceKP.Address(),
 			"transaction", tx.GetHash(),
 			"error", err,
+			"error-class", ErrorClassOf(err),
 		)
 		return
 	}
+	postAck := time.Now()
+	timings.PostAck = FormatRecordTime(postAck)
 
+	// NOTE elapsed is fixed when the transaction is confirmed or timed out,
+	// so the requests after that, like getting block, are not included.
+	started := time.Now()
+	var elapsed int64
 	defer func(t time.Time, l logging.Logger) {
+		if elapsed == 0 {
+			elapsed = ElapsedTime(t)
+		}
+
 		h.result.Write(
 			"payment",
-			"elapsed", ElapsedTime(t),
+			"elapsed", elapsed,
 			"count", len(targets),
 			"addresses", targets,
 			"amount", amount,
 			"source", sourceKP.Address(),
 			"transaction", tx.GetHash(),
 			"error", err,
+			"error-class", ErrorClassOf(err),
+			"timings", timings,
+			"submit-height", submitHeight,
+			"inclusion-height", inclusionHeight,
 		)
-	}(time.Now(), log_)
+	}(started, log_)
 
 	// check transaction is stored in block
-	done := make(chan Transaction)
+	type polled struct {
+		ctx    Transaction
+		time   time.Time // NOTE when the successful poll started
+		missed time.Time // NOTE when the last unsuccessful poll started
+	}
+	done := make(chan polled, 1)
+	stop := make(chan bool)
+	defer close(stop)
 
+	timings.FirstPoll = FormatRecordTime(time.Now())
 	go func() {
-		var ctx Transaction
+		// NOTE the transaction is not in block before it is accepted
+		missed := postAck
 		for {
-			if ctx, err = h.GetTransaction(tx.GetHash(), true); err == nil {
-				err = nil
-				done <- ctx
+			t := time.Now()
+			if ctx, err := h.GetTransaction(client, tx.GetHash(), true); err == nil {
+				done <- polled{ctx: ctx, time: t, missed: missed}
+				return
+			}
+			missed = t
+
+			select {
+			case <-stop:
 				return
+			case <-time.After(time.Duration(300) * time.Millisecond):
 			}
-			time.Sleep(time.Duration(300) * time.Millisecond)
 		}
 	}()
 
 	select {
-	case ctx := <-done:
+	case p := <-done:
+		confirmed := time.Now()
+		elapsed = confirmed.Sub(started).Nanoseconds()
+		timings.Confirmed = FormatRecordTime(confirmed)
+		timings.MissedPoll = FormatRecordTime(p.missed)
+		timings.LastPoll = FormatRecordTime(p.time)
 		log_.Debug(
 			"payment transaction confirmed",
-			"confirmed transaction", ctx,
+			"confirmed transaction", p.ctx,
 		)
+
+		if len(p.ctx.Block) > 0 {
+			if height, err := h.GetBlockHeight(client, p.ctx.Block); err != nil {
+				log_.Error("failed to get block", "block", p.ctx.Block, "error", err)
+			} else {
+				inclusionHeight = height
+			}
+		}
 	case <-time.After(h.ConfirmDuration):
-		err = fmt.Errorf("timeout: %v", h.ConfirmDuration)
+		elapsed = ElapsedTime(started)
+		err = &ErrorConfirmTimeout{Duration: h.ConfirmDuration}
 		log_.Error(
 			"payment transaction failed to confirm",
 			"error", "timeout",
@@ -531,7 +835,7 @@ func (h *Hotter) payment(sourceKP *keypair.Full, amount common.Amount, targets .
 	return
 }
 
-func (h *Hotter) sendTransaction(tx transaction.Transaction) (err error) {
+func (h *Hotter) sendTransaction(client *HTTP2Client, tx transaction.Transaction) (err error) {
 	log_ := log.New(logging.Ctx{"m": "sendTransaction", "uid": common.GenerateUUID()})
 
 	var b []byte
@@ -543,7 +847,7 @@ func (h *Hotter) sendTransaction(tx transaction.Transaction) (err error) {
 
 	retries := 3
 	for i := 0; i < 3; i++ { // retry
-		b, err = h.Client().Post(
+		b, err = client.Post(
 			fmt.Sprintf("%s/%s/transactions", network.UrlPathPrefixAPI, api.APIVersionV1),
 			body,
 			nil,
@@ -589,6 +893,7 @@ func (h *Hotter) sendTransaction(tx transaction.Transaction) (err error) {
 
 	if err != nil {
 		log_.Error("response", "body", string(b), "error", err, "error-type", fmt.Sprintf("%T", err))
+		err = NewClassifiedError(err)
 	} else {
 		log_.Debug("response", "body", string(b))
 	}
@@ -597,17 +902,28 @@ func (h *Hotter) sendTransaction(tx transaction.Transaction) (err error) {
 }
 
 func (h *Hotter) request(address string) (err error) {
-	account, _ := h.GetAccount(address, true)
+	// NOTE one endpoint is chosen for every request, so the retries and the
+	// polls do not change the order of endpoints
+	client := h.Client(address)
+
+	account, _ := h.getAccount(client, address, true)
 	if account.Empty() {
 		err = fmt.Errorf("failed to get account: %v", address)
 		return
 	}
 
+	h.RLock()
+	cachedAddresses := h.cachedAddresses[address]
+	operations := h.Operations
+	kp := h.keys[address]
+	r := h.rands[address]
+	h.RUnlock()
+
 	var addresses []string
 	for {
 		//addresses = PickKeysRandom(h.createdAccounts, h.Operations, address)
 		//addresses = PickKeysRandom(h.createdAccounts, 1, address)
-		addresses = PickKeysRandom2(h.cachedAddresses[address], h.Operations)
+		addresses = PickKeysRandom2(r, cachedAddresses, operations)
 		if len(addresses) > 0 {
 			break
 		}
@@ -628,7 +944,7 @@ func (h *Hotter) request(address string) (err error) {
 		return
 	}
 
-	err = h.payment(h.keys[address], common.Amount(1), targets...)
+	err = h.payment(client, kp, common.Amount(1), targets...)
 
 	return
 }
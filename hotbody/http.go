@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"golang.org/x/net/http2"
@@ -17,11 +18,23 @@ import (
 )
 
 type HTTP2Client struct {
+	sync.RWMutex
 	timeout   time.Duration
 	url       *url.URL
 	client    http.Client
 	transport *http.Transport
 	headers   http.Header
+	stat      HTTP2ClientStat
+}
+
+type HTTP2ClientStat struct {
+	Endpoint    string        `json:"endpoint"`
+	Requests    uint64        `json:"requests"`
+	Errors      uint64        `json:"errors"`
+	Problems    uint64        `json:"problems"`
+	LastElapsed time.Duration `json:"last-elapsed"`
+	LastError   string        `json:"last-error"`
+	LastTime    time.Time     `json:"last-time"`
 }
 
 func NewHTTP2Client(timeout time.Duration, url *url.URL, headers http.Header) (http2Client *HTTP2Client, err error) {
@@ -54,6 +67,7 @@ func NewHTTP2Client(timeout time.Duration, url *url.URL, headers http.Header) (h
 		client:    client,
 		transport: transport,
 		headers:   headers,
+		stat:      HTTP2ClientStat{Endpoint: url.String()},
 	}
 
 	return
@@ -67,6 +81,36 @@ func (client *HTTP2Client) Transport() *http.Transport {
 	return client.transport
 }
 
+// Stat returns the request statistics of this client.
+func (client *HTTP2Client) Stat() HTTP2ClientStat {
+	client.RLock()
+	defer client.RUnlock()
+
+	return client.stat
+}
+
+func (client *HTTP2Client) updateStat(started time.Time, err error) {
+	client.Lock()
+	defer client.Unlock()
+
+	client.stat.Requests++
+	client.stat.LastElapsed = time.Since(started)
+	client.stat.LastTime = time.Now()
+	if err == nil {
+		return
+	}
+
+	// NOTE non-200 responses are counted separately from the connection
+	// errors; polling the unconfirmed transactions always returns 404.
+	if _, ok := err.(*errors.Error); ok {
+		client.stat.Problems++
+		return
+	}
+
+	client.stat.Errors++
+	client.stat.LastError = err.Error()
+}
+
 func (client *HTTP2Client) resolvePath(path string) *url.URL {
 	return client.url.ResolveReference(&url.URL{Path: path})
 }
@@ -86,7 +130,7 @@ func (client *HTTP2Client) newHeaders(headers http.Header) http.Header {
 	return newHeaders
 }
 
-func (client *HTTP2Client) request(method, path string, body io.Reader, headers http.Header) (response *http.Response, err error) {
+func (client *HTTP2Client) request(ctx context.Context, method, path string, body io.Reader, headers http.Header) (response *http.Response, err error) {
 	u := client.resolvePath(path)
 
 	var r *http.Request
@@ -100,9 +144,9 @@ func (client *HTTP2Client) request(method, path string, body io.Reader, headers
 	r.Header = client.newHeaders(headers)
 
 	if client.timeout > 0 {
-		ctx, _ := context.WithTimeout(context.TODO(), client.timeout)
-		r = r.WithContext(ctx)
+		ctx, _ = context.WithTimeout(ctx, client.timeout)
 	}
+	r = r.WithContext(ctx)
 
 	response, err = client.client.Do(r)
 
@@ -110,8 +154,17 @@ func (client *HTTP2Client) request(method, path string, body io.Reader, headers
 }
 
 func (client *HTTP2Client) Get(path string, headers http.Header) (b []byte, err error) {
+	return client.GetContext(context.Background(), path, headers)
+}
+
+// GetContext is Get, which is canceled with ctx.
+func (client *HTTP2Client) GetContext(ctx context.Context, path string, headers http.Header) (b []byte, err error) {
+	defer func(t time.Time) {
+		client.updateStat(t, err)
+	}(time.Now())
+
 	var response *http.Response
-	if response, err = client.request("GET", path, nil, headers); err != nil {
+	if response, err = client.request(ctx, "GET", path, nil, headers); err != nil {
 		return
 	}
 	defer response.Body.Close()
@@ -130,6 +183,10 @@ func (client *HTTP2Client) Get(path string, headers http.Header) (b []byte, err
 }
 
 func (client *HTTP2Client) Post(path string, body []byte, headers http.Header) (b []byte, err error) {
+	defer func(t time.Time) {
+		client.updateStat(t, err)
+	}(time.Now())
+
 	var bodyReader io.Reader
 
 	if body != nil {
@@ -137,7 +194,7 @@ func (client *HTTP2Client) Post(path string, body []byte, headers http.Header) (
 	}
 
 	var response *http.Response
-	if response, err = client.request("POST", path, bodyReader, headers); err != nil {
+	if response, err = client.request(context.Background(), "POST", path, bodyReader, headers); err != nil {
 		return
 	}
 	defer response.Body.Close()
@@ -0,0 +1,285 @@
+package hotbody
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BaseRecord is the common part of records, the time, type, schema version,
+// run ID and sequence number. The record without elapsed time and error, like
+// `started`, embeds it as it is.
+type BaseRecord struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Run     string `json:"run,omitempty"`
+	Seq     uint64 `json:"seq"`
+}
+
+func (r BaseRecord) GetTime() time.Time {
+	t, _ := ParseRecordTime(r.Time)
+	return t
+}
+
+// GetSequence returns the sequence number in the run; v0 record does not
+// have it.
+func (r BaseRecord) GetSequence() (seq uint64, found bool) {
+	return r.Seq, r.Version > 0
+}
+
+func (r BaseRecord) GetType() string {
+	return r.Type
+}
+
+func (r BaseRecord) GetElapsed() int64 {
+	return 0
+}
+
+func (r BaseRecord) GetRawError() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (r BaseRecord) GetError() error {
+	return nil
+}
+
+func (r BaseRecord) GetErrorType() RecordErrorType {
+	return RecordErrorUnknown
+}
+
+func (r BaseRecord) GetErrorClass() RecordErrorClass {
+	return RecordErrorClass{}
+}
+
+// BaseResultRecord is the base of the records of request, which have the
+// elapsed time and error.
+type BaseResultRecord struct {
+	BaseRecord
+	Elapsed    int64                  `json:"elapsed"` // NOTE nanoseconds
+	Error      map[string]interface{} `json:"error"`
+	ErrorClass *RecordErrorClass      `json:"error-class"`
+}
+
+func (r BaseResultRecord) GetElapsed() int64 {
+	return r.Elapsed
+}
+
+func (r BaseResultRecord) GetRawError() map[string]interface{} {
+	return r.Error
+}
+
+func (r BaseResultRecord) GetError() error {
+	if len(r.Error) < 1 && r.ErrorClass == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%v", r.Error)
+}
+
+func (r BaseResultRecord) GetErrorClass() RecordErrorClass {
+	if r.ErrorClass != nil {
+		return *r.ErrorClass
+	}
+
+	return ParseRecordError(r.Error)
+}
+
+func (r BaseResultRecord) GetErrorType() RecordErrorType {
+	return RecordErrorType(r.GetErrorClass().Key())
+}
+
+// RecordDecoder decodes the line of <result log> into the record.
+type RecordDecoder func(b []byte) (Record, error)
+
+// NewJSONRecordDecoder returns the decoder, which unmarshals the line into the
+// new value of the same type with the given record.
+func NewJSONRecordDecoder(record Record) RecordDecoder {
+	t := reflect.TypeOf(record)
+	return func(b []byte) (Record, error) {
+		v := reflect.New(t)
+		if err := json.Unmarshal(b, v.Interface()); err != nil {
+			return nil, err
+		}
+
+		return v.Elem().Interface().(Record), nil
+	}
+}
+
+// ErrorUnknownRecordType is returned when the type of record is not
+// registered.
+type ErrorUnknownRecordType struct {
+	Type string
+}
+
+func (e *ErrorUnknownRecordType) Error() string {
+	return fmt.Sprintf("unknown type found: %v", e.Type)
+}
+
+var (
+	recordTypesLock sync.RWMutex
+	recordTypes     = map[string]map[int]RecordDecoder{} // NOTE decoders by version
+)
+
+// RegisterRecordType registers the decoder of the record type for the
+// version; the record is decoded by the decoder of the highest version, which
+// is not higher than the version of record.
+func RegisterRecordType(recordType string, version int, decoder RecordDecoder) {
+	recordTypesLock.Lock()
+	defer recordTypesLock.Unlock()
+
+	if _, found := recordTypes[recordType]; !found {
+		recordTypes[recordType] = map[int]RecordDecoder{}
+	}
+	recordTypes[recordType][version] = decoder
+}
+
+// RegisterRecord registers the record type, which is decoded from JSON by
+// it's struct; the v0 record is upgraded before decoding.
+func RegisterRecord(recordType string, record Record) {
+	decoder := NewJSONRecordDecoder(record)
+	RegisterRecordType(recordType, RecordVersion, decoder)
+	RegisterRecordType(recordType, 0, NewV0RecordDecoder(decoder))
+}
+
+// RecordTypes returns the registered record types.
+func RecordTypes() (types []string) {
+	recordTypesLock.RLock()
+	defer recordTypesLock.RUnlock()
+
+	for t := range recordTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return
+}
+
+// DecodeRecord decodes the line of <result log> by it's type and version; the
+// unregistered type returns ErrorUnknownRecordType.
+func DecodeRecord(b []byte) (record Record, err error) {
+	var head struct {
+		Type    *string `json:"type"`
+		Version int     `json:"version"`
+	}
+	if err = json.Unmarshal(b, &head); err != nil {
+		return
+	}
+	if head.Type == nil {
+		err = fmt.Errorf("found invalid format")
+		return
+	}
+
+	recordTypesLock.RLock()
+	decoders, found := recordTypes[*head.Type]
+	recordTypesLock.RUnlock()
+	if !found {
+		err = &ErrorUnknownRecordType{Type: *head.Type}
+		return
+	}
+
+	decoderVersion := -1
+	for v := range decoders {
+		if v <= head.Version && v > decoderVersion {
+			decoderVersion = v
+		}
+	}
+	if decoderVersion < 0 {
+		err = fmt.Errorf("unsupported version of %s: %d", *head.Type, head.Version)
+		return
+	}
+
+	return decoders[decoderVersion](b)
+}
+
+// SummaryRow is the row of the summary of RecordAggregator.
+type SummaryRow struct {
+	Key   string
+	Value interface{}
+	Text  string // NOTE if empty, Value is printed
+}
+
+// RecordAggregator collects the records of the types, which it is registered
+// for, and summarizes them in the result.
+type RecordAggregator interface {
+	Name() string
+	Add(Record)
+	Summary() []SummaryRow
+}
+
+type registeredAggregator struct {
+	types []string
+	new   func() RecordAggregator
+}
+
+var (
+	recordAggregatorsLock sync.RWMutex
+	recordAggregators     []registeredAggregator
+)
+
+// RegisterRecordAggregator registers the aggregator for the record types; the
+// new aggregator is created for every result.
+func RegisterRecordAggregator(newAggregator func() RecordAggregator, recordTypes ...string) {
+	recordAggregatorsLock.Lock()
+	defer recordAggregatorsLock.Unlock()
+
+	recordAggregators = append(recordAggregators, registeredAggregator{types: recordTypes, new: newAggregator})
+}
+
+// RecordAggregators is the set of the registered aggregators.
+type RecordAggregators struct {
+	aggregators []RecordAggregator
+	byType      map[string][]RecordAggregator
+}
+
+func NewRecordAggregators() *RecordAggregators {
+	recordAggregatorsLock.RLock()
+	defer recordAggregatorsLock.RUnlock()
+
+	a := &RecordAggregators{byType: map[string][]RecordAggregator{}}
+	for _, r := range recordAggregators {
+		aggregator := r.new()
+		a.aggregators = append(a.aggregators, aggregator)
+		for _, t := range r.types {
+			a.byType[t] = append(a.byType[t], aggregator)
+		}
+	}
+
+	return a
+}
+
+// Add passes the record to the aggregators of it's type.
+func (a *RecordAggregators) Add(record Record) {
+	for _, aggregator := range a.byType[record.GetType()] {
+		aggregator.Add(record)
+	}
+}
+
+func (a *RecordAggregators) Aggregators() []RecordAggregator {
+	return a.aggregators
+}
+
+func decodeConfigRecord(b []byte) (Record, error) {
+	var d struct {
+		Config HotterConfig `json:"config"`
+	}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, err
+	}
+
+	return d.Config, nil
+}
+
+func init() {
+	RegisterRecordType("config", 0, decodeConfigRecord)
+	RegisterRecord("started", RecordMark{})
+	RegisterRecord("ended", RecordMark{})
+	RegisterRecord("create-accounts", RecordCreateAccounts{})
+	RegisterRecord("payment", RecordPayment{})
+	RegisterRecord("sebak-error", RecordSEBAKError{})
+	RegisterRecord("control", RecordControl{})
+	RegisterRecord("account-stopped", RecordAccountStopped{})
+}
@@ -0,0 +1,216 @@
+package hotbody
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/common"
+)
+
+// ReplayConfig is the source of replaying, it is recorded in `config`.
+type ReplayConfig struct {
+	Source string  `json:"source"` // NOTE replayed <result log>
+	Run    string  `json:"run,omitempty"`
+	Speed  float64 `json:"speed"`
+}
+
+// ReplayPayment is the payment of the previous running, which will be sent
+// again.
+type ReplayPayment struct {
+	Offset  time.Duration // NOTE from when the first payment was sent
+	Source  string
+	Targets []string
+	Amount  common.Amount
+}
+
+// NewReplayPayments extracts the payments from `payment` and `sebak-error`
+// records in the order of they were sent; the failed payments are also
+// included.
+func NewReplayPayments(records []Record) (payments []ReplayPayment, err error) {
+	var sent []time.Time
+	for _, r := range records {
+		var p ReplayPayment
+		var t time.Time
+		switch record := r.(type) {
+		case RecordPayment:
+			p = ReplayPayment{Source: record.Source, Targets: record.Addresses, Amount: record.Amount}
+			t = record.GetTime().Add(-time.Duration(record.GetElapsed()))
+			if record.Timings != nil && len(record.Timings.SignStart) > 0 {
+				if s, e := ParseRecordTime(record.Timings.SignStart); e == nil {
+					t = s
+				}
+			}
+		case RecordSEBAKError:
+			if record.When != "payment" {
+				continue
+			}
+			p = ReplayPayment{Source: record.Source, Targets: record.Addresses, Amount: record.Amount}
+			t = record.GetTime()
+		default:
+			continue
+		}
+
+		if len(p.Source) < 1 || len(p.Targets) < 1 {
+			err = fmt.Errorf("source or target of payment not found at %s; too old <result log> to replay", FormatRecordTime(r.GetTime()))
+			return
+		}
+		if p.Amount < 1 {
+			p.Amount = common.Amount(1)
+		}
+
+		payments = append(payments, p)
+		sent = append(sent, t)
+	}
+
+	if len(payments) < 1 {
+		return
+	}
+
+	index := make([]int, len(payments))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return sent[index[i]].Before(sent[index[j]])
+	})
+
+	sorted := make([]ReplayPayment, len(payments))
+	first := sent[index[0]]
+	for i, j := range index {
+		sorted[i] = payments[j]
+		sorted[i].Offset = sent[j].Sub(first)
+	}
+
+	return sorted, nil
+}
+
+// ReplayAccounts returns the addresses of payments in the order of they
+// appear.
+func ReplayAccounts(payments []ReplayPayment) (addresses []string) {
+	found := map[string]bool{}
+	add := func(address string) {
+		if !found[address] {
+			found[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, p := range payments {
+		add(p.Source)
+		for _, target := range p.Targets {
+			add(target)
+		}
+	}
+
+	return
+}
+
+// Replay creates the new accounts for the accounts of payments and sends the
+// payments at their offset from started, which is divided by speed. Every
+// payment is confirmed in it's own goroutine, so the slow confirmation does
+// not delay the next payments; the payments after the deadline by `Timeout`
+// are not sent.
+func (h *Hotter) Replay(payments []ReplayPayment, speed float64) (err error) {
+	if speed <= 0 {
+		return fmt.Errorf("invalid speed, %v", speed)
+	}
+
+	log.Debug("replay started", "payments", len(payments), "speed", speed)
+
+	var initAccount BlockAccount
+	if initAccount, err = h.GetAccount(h.KP.Address(), false); err != nil {
+		return
+	}
+	if initAccount.Balance < 1 {
+		err = fmt.Errorf("init account does not have enough balance: %v", initAccount.Balance)
+		return
+	}
+
+	accounts := ReplayAccounts(payments)
+
+	var created []string
+	if created, err = h.prepareAccounts(context.Background(), len(accounts)); err != nil {
+		return
+	}
+
+	// NOTE the recorded accounts are replaced by the new accounts
+	replaced := map[string]string{}
+	for i, address := range accounts {
+		replaced[address] = created[i]
+	}
+
+	h.runningAccounts = &RunningAccounts{}
+
+	watchStopChan := make(chan bool)
+	go h.watchBlock(watchStopChan)
+
+	h.Lock()
+	h.started = time.Now()
+	h.deadline = h.started.Add(h.Timeout)
+	started := h.started
+	h.Unlock()
+
+	h.result.Write("started")
+
+	var wg sync.WaitGroup
+	for i, p := range payments {
+		at := started.Add(time.Duration(float64(p.Offset) / speed))
+
+		h.RLock()
+		deadline := h.deadline
+		h.RUnlock()
+		if at.After(deadline) {
+			log.Debug("deadline reached; the remaining payments are not sent", "remaining", len(payments)-i)
+			break
+		}
+
+		time.Sleep(time.Until(at))
+
+		address := replaced[p.Source]
+		var targets []string
+		for _, target := range p.Targets {
+			targets = append(targets, replaced[target])
+		}
+
+		amount := p.Amount
+
+		// NOTE the endpoint is chosen before the goroutine, so the same seed
+		// chooses the same endpoints in the order of payments
+		client := h.Client(address)
+
+		h.RLock()
+		kp := h.keys[address]
+		h.RUnlock()
+
+		// NOTE the payments of same source can be running at the same time,
+		// so every payment is counted in running
+		key := fmt.Sprintf("%s-%d", address, i)
+		h.runningAccounts.SetActive(key)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer h.runningAccounts.SetDeactive(key)
+
+			if err := h.payment(client, kp, amount, targets...); err != nil {
+				log_ := log.New(logging.Ctx{"m": "replay", "address": A(address)})
+				log_.Error("request failed", "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	h.result.Write("ended")
+
+	close(watchStopChan)
+	h.result.Close()
+
+	log.Debug("replay ended")
+
+	return
+}
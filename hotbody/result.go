@@ -4,13 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-
-	"boscoin.io/sebak/lib/common"
+	"sync"
+	"time"
 )
 
 type Result struct {
-	config HotterConfig
-	output *os.File
+	sync.RWMutex
+	config    HotterConfig
+	output    *os.File
+	listeners []func([]byte)
+	run       string
+	seq       uint64
+
+	// NOTE the written records are queued and passed to the listeners by
+	// notify(), so the listeners do not block writing records.
+	queueLock   sync.Mutex
+	queueCond   *sync.Cond
+	queue       [][]byte
+	queueClosed bool
+	notified    chan struct{} // NOTE closed when notify() is finished
 }
 
 func NewResult(config HotterConfig) (result *Result, err error) {
@@ -22,19 +34,110 @@ func NewResult(config HotterConfig) (result *Result, err error) {
 	result = &Result{
 		config: config,
 		output: output,
+		run:    NewRunID(),
 	}
+	result.queueCond = sync.NewCond(&result.queueLock)
 
-	result.write(map[string]interface{}{"type": "config", "config": config, "time": common.NowISO8601()})
+	result.write(map[string]interface{}{"type": "config", "config": config, "time": FormatRecordTime(time.Now())})
 
 	return
 }
 
+// AddListener registers the function, which will receive every serialized
+// record line after it is written to the result output. The listeners are
+// called one by one in the order of records in one goroutine, not under the
+// lock of Result, so the listener must return quickly; the next records wait
+// for it.
+func (r *Result) AddListener(f func([]byte)) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.listeners = append(r.listeners, f)
+	if r.notified == nil {
+		r.notified = make(chan struct{})
+		go r.notify()
+	}
+}
+
+// Close closes the result output and waits until the queued records are
+// passed to the listeners.
 func (r *Result) Close() {
-	r.output.Close()
+	r.Lock()
+	if r.output != nil {
+		r.output.Close()
+		r.output = nil
+	}
+	notified := r.notified
+	r.Unlock()
+
+	r.queueLock.Lock()
+	r.queueClosed = true
+	r.queueCond.Broadcast()
+	r.queueLock.Unlock()
+
+	if notified != nil {
+		<-notified
+	}
+}
+
+func (r *Result) enqueue(b []byte) {
+	r.queueLock.Lock()
+	defer r.queueLock.Unlock()
+
+	r.queue = append(r.queue, b)
+	r.queueCond.Signal()
+}
+
+// notify passes the queued records to the listeners until Result is closed.
+func (r *Result) notify() {
+	defer close(r.notified)
+
+	for {
+		r.queueLock.Lock()
+		for len(r.queue) < 1 && !r.queueClosed {
+			r.queueCond.Wait()
+		}
+		queue := r.queue
+		r.queue = nil
+		r.queueLock.Unlock()
+
+		if len(queue) < 1 { // NOTE closed
+			return
+		}
+
+		r.RLock()
+		listeners := r.listeners
+		r.RUnlock()
+
+		for _, b := range queue {
+			for _, f := range listeners {
+				f(b)
+			}
+		}
+	}
+}
+
+// Run returns the run ID, which every record has.
+func (r *Result) Run() string {
+	return r.run
 }
 
-func (r *Result) write(o interface{}) {
-	b, err := json.Marshal(o)
+// write writes the record with the schema version, run ID and sequence
+// number; the sequence number follows the order in <result log>.
+func (r *Result) write(d map[string]interface{}) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.output == nil { // NOTE already closed
+		return
+	}
+
+	d["version"] = RecordVersion
+	d["run"] = r.run
+	d["seq"] = r.seq
+	r.seq++
+
+	b, err := json.Marshal(d)
 	if err != nil {
 		panic(err)
 	}
@@ -42,6 +145,10 @@ func (r *Result) write(o interface{}) {
 	if _, err := fmt.Fprintln(r.output, string(b)); err != nil {
 		panic(err)
 	}
+
+	if len(r.listeners) > 0 {
+		r.enqueue(b)
+	}
 }
 
 func (r *Result) Write(t string, args ...interface{}) {
@@ -51,7 +158,7 @@ func (r *Result) Write(t string, args ...interface{}) {
 
 	d := map[string]interface{}{
 		"type": t,
-		"time": common.NowISO8601(),
+		"time": FormatRecordTime(time.Now()),
 	}
 
 	for i := 0; i < len(args)-1; i = i + 2 {
@@ -0,0 +1,277 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/node"
+	"github.com/stellar/go/keypair"
+
+	"github.com/spikeekips/sebak-hot-body/hotbody"
+	"github.com/spikeekips/sebak-hot-body/hotbody/analysis"
+)
+
+const (
+	DefaultT               int           = 10
+	DefaultOperations      int           = 1
+	DefaultRequestTimeout  time.Duration = time.Second * 30
+	DefaultConfirmDuration time.Duration = time.Second * 60
+	UserAgent              string        = "sebak-hot-body/v1.0"
+)
+
+// RecordHook receives every record of running after it is written. The hooks
+// are serialized, they are called one by one in the order of records in one
+// goroutine, so the hook must not block; the next records wait for it. Run
+// returns after every record is passed to the hooks.
+type RecordHook func(hotbody.Record)
+
+// AttachFunc is called with the hotter before it starts, like starting the
+// control API; the returned detach is called after running, even with error.
+type AttachFunc func(*hotbody.Hotter) (detach func(), err error)
+
+// Config is the config of Run; the zero values except `Endpoints`, `KP` and
+// `Timeout` are replaced by the defaults.
+type Config struct {
+	Endpoints       []string      // NOTE SEBAK endpoints, like 'http://127.0.0.1:12345'
+	KP              *keypair.Full // NOTE init account
+	T               int
+	Operations      int
+	Timeout         time.Duration
+	RequestTimeout  time.Duration
+	ConfirmDuration time.Duration
+	ResultOutput    string // NOTE if empty, <result log> is not kept after running
+	Seed            int64  // NOTE if 0, new seed is given
+	Filter          analysis.Filter
+	Options         analysis.Options
+	Hooks           []RecordHook
+	Attach          []AttachFunc
+}
+
+func (c *Config) setDefaults() (err error) {
+	if len(c.Endpoints) < 1 {
+		return errors.New("endpoints must be given")
+	}
+	if c.KP == nil {
+		return errors.New("init account must be given")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be given")
+	}
+
+	if c.T < 1 {
+		c.T = DefaultT
+	}
+	if c.Operations < 1 {
+		c.Operations = DefaultOperations
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultRequestTimeout
+	}
+	if c.ConfirmDuration <= 0 {
+		c.ConfirmDuration = DefaultConfirmDuration
+	}
+
+	return
+}
+
+// NewClients creates the clients of SEBAK endpoints.
+func NewClients(endpoints []*common.Endpoint, requestTimeout time.Duration, t int) (clients []*hotbody.HTTP2Client, err error) {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("User-Agent", UserAgent)
+
+	for _, i := range endpoints {
+		var client *hotbody.HTTP2Client
+		if client, err = hotbody.NewHTTP2Client(requestTimeout, (*url.URL)(i), headers); err != nil {
+			err = fmt.Errorf("failed to create HTTP2Client: %v", err)
+			return
+		}
+		client.Transport().MaxIdleConnsPerHost = t + 100
+		clients = append(clients, client)
+	}
+
+	return
+}
+
+// GetNodeInfo requests the node info to every client; the node info of the
+// last client is returned.
+func GetNodeInfo(ctx context.Context, clients []*hotbody.HTTP2Client) (nodeInfo node.NodeInfo, err error) {
+	for _, client := range clients {
+		var b []byte
+		if b, err = client.GetContext(ctx, "/", nil); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				return
+			}
+			err = &ErrorNodeUnreachable{URL: client.URL(), Err: err}
+			return
+		}
+
+		if nodeInfo, err = node.NewNodeInfoFromJSON(b); err != nil {
+			err = fmt.Errorf("failed to parse node info response: %v", err)
+			return
+		}
+		log.Debug("sebak info", "sebak", client.URL())
+		log.Debug(fmt.Sprintf(
+			`================================================================================
+%s
+================================================================================
+`, b))
+	}
+
+	return
+}
+
+// ErrorNodeUnreachable is returned, when the node info can not be requested.
+type ErrorNodeUnreachable struct {
+	URL *url.URL
+	Err error
+}
+
+func (e *ErrorNodeUnreachable) Error() string {
+	return e.Err.Error()
+}
+
+// collector keeps the records of running in memory.
+type collector struct {
+	sync.Mutex
+	l     analysis.Log
+	hooks []RecordHook
+}
+
+func (c *collector) add(b []byte) {
+	record, err := hotbody.DecodeRecord(b)
+	if err != nil {
+		log.Error("failed to decode record", "error", err)
+		return
+	} else if record == nil {
+		return
+	}
+
+	c.Lock()
+	c.l.AddRecord(record)
+	c.Unlock()
+
+	for _, hook := range c.hooks {
+		hook(record)
+	}
+}
+
+// Run runs hot-body and returns the summary of the records. When ctx is
+// canceled, the running is ended like `end` of control API and the summary of
+// the records until then is returned with the error of ctx; before started,
+// like requesting node info and creating accounts, it stops with the error of
+// ctx.
+func Run(ctx context.Context, config Config) (summary analysis.Summary, err error) {
+	if err = config.setDefaults(); err != nil {
+		return
+	}
+
+	var endpoints []*common.Endpoint
+	for _, i := range config.Endpoints {
+		var endpoint *common.Endpoint
+		if endpoint, err = common.ParseEndpoint(i); err != nil {
+			err = fmt.Errorf("invalid endpoint, '%s'; %v", i, err)
+			return
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	var clients []*hotbody.HTTP2Client
+	if clients, err = NewClients(endpoints, config.RequestTimeout, config.T); err != nil {
+		return
+	}
+
+	var nodeInfo node.NodeInfo
+	if nodeInfo, err = GetNodeInfo(ctx, clients); err != nil {
+		return
+	}
+
+	resultOutput := config.ResultOutput
+	if len(resultOutput) < 1 {
+		var f *os.File
+		if f, err = ioutil.TempFile("", "hot-body-result-"); err != nil {
+			return
+		}
+		f.Close()
+		resultOutput = f.Name()
+		defer os.Remove(resultOutput)
+	}
+
+	hotterConfig := hotbody.HotterConfig{
+		Node:            nodeInfo,
+		T:               config.T,
+		KP:              config.KP,
+		InitAccount:     config.KP.Address(),
+		Timeout:         config.Timeout,
+		RequestTimeout:  config.RequestTimeout,
+		ConfirmDuration: config.ConfirmDuration,
+		ResultOutput:    resultOutput,
+		Operations:      config.Operations,
+		Seed:            config.Seed,
+	}
+
+	created := time.Now()
+
+	var hotter *hotbody.Hotter
+	if hotter, err = hotbody.NewHotter(hotterConfig, clients); err != nil {
+		return
+	}
+
+	// NOTE the config record is written before the listener is added
+	c := &collector{
+		l: analysis.Log{
+			Config:  hotterConfig,
+			Created: created,
+			Version: hotbody.RecordVersion,
+			Run:     hotter.Result().Run(),
+		},
+		hooks: config.Hooks,
+	}
+	hotter.Result().AddListener(c.add)
+
+	if _, err = hotter.GetAccount(config.KP.Address(), true); err != nil {
+		hotter.Result().Close()
+		err = fmt.Errorf("account of init account not found; %v", err)
+		return
+	}
+
+	for _, attach := range config.Attach {
+		var detach func()
+		if detach, err = attach(hotter); err != nil {
+			hotter.Result().Close()
+			return
+		}
+		defer detach()
+	}
+
+	if err = hotter.StartContext(ctx); err != nil {
+		hotter.Result().Close()
+		return
+	}
+
+	c.Lock()
+	l := c.l
+	c.Unlock()
+
+	if l, err = analysis.FilterLog(l, config.Filter); err != nil {
+		return
+	}
+	summary = analysis.NewSummary(l, config.Options)
+
+	if len(l.Records) < 1 {
+		err = analysis.ErrNoRecords
+	} else if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	return
+}
@@ -0,0 +1,150 @@
+package hotbody
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"boscoin.io/sebak/lib/common"
+)
+
+// RecordVersion is the schema version of <result log>, which `go` writes.
+//
+// * v0: `time` is ISO8601 without time zone and `elapsed` is the seconds
+// string with 10 decimals
+// * v1: `time` is RFC3339Nano in UTC and `elapsed` is nanoseconds; every
+// record has `version`, `run`, the run ID, and `seq`, the sequence number in
+// the run
+const RecordVersion int = 1
+
+// iso8601WithoutZone is the time format of v0 records, which do not have time
+// zone; it is regarded as UTC.
+const iso8601WithoutZone string = "2006-01-02T15:04:05.000000000"
+
+// FormatRecordTime formats the time of record in RFC3339Nano.
+func FormatRecordTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ParseRecordTime parses the time of record; RFC3339Nano or ISO8601 of v0.
+func ParseRecordTime(s string) (t time.Time, err error) {
+	if t, err = time.Parse(time.RFC3339Nano, s); err == nil {
+		return
+	}
+	if t, err = common.ParseISO8601(s); err == nil {
+		return
+	}
+
+	return time.Parse(iso8601WithoutZone, s)
+}
+
+// NewRunID returns the new ID of running `go`.
+func NewRunID() string {
+	return common.GenerateUUID()
+}
+
+func upgradeRecordTimeV0(m map[string]interface{}, key string) error {
+	s, ok := m[key].(string)
+	if !ok || len(s) < 1 {
+		return nil
+	}
+
+	t, err := ParseRecordTime(s)
+	if err != nil {
+		return fmt.Errorf("invalid %s, '%s'; %v", key, s, err)
+	}
+	m[key] = FormatRecordTime(t)
+
+	return nil
+}
+
+// UpgradeRecordV0 upgrades the v0 record to the current version in place; the
+// `run` and `seq` are not set, because v0 does not have them.
+func UpgradeRecordV0(m map[string]interface{}) (err error) {
+	if err = upgradeRecordTimeV0(m, "time"); err != nil {
+		return
+	}
+
+	if s, ok := m["elapsed"].(string); ok {
+		var elapsed int64
+		if elapsed, err = ParseRecordElapsedTime(s); err != nil {
+			return fmt.Errorf("invalid elapsed, '%s'; %v", s, err)
+		}
+		m["elapsed"] = elapsed
+	}
+
+	if timings, ok := m["timings"].(map[string]interface{}); ok {
+		for key := range timings {
+			if err = upgradeRecordTimeV0(timings, key); err != nil {
+				return
+			}
+		}
+	}
+
+	m["version"] = RecordVersion
+
+	return
+}
+
+// unmarshalRecordMap unmarshals the record into map; the numbers are kept as
+// they are.
+func unmarshalRecordMap(b []byte) (m map[string]interface{}, err error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&m)
+
+	return
+}
+
+// NewV0RecordDecoder returns the decoder of v0 record, which upgrades the
+// record and decodes it by the decoder of current version.
+func NewV0RecordDecoder(decoder RecordDecoder) RecordDecoder {
+	return func(b []byte) (Record, error) {
+		m, err := unmarshalRecordMap(b)
+		if err != nil {
+			return nil, err
+		}
+		if err = UpgradeRecordV0(m); err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(m); err != nil {
+			return nil, err
+		}
+
+		return decoder(b)
+	}
+}
+
+// MigrateRecord upgrades the line of <result log> to the current version; the
+// v0 record gets the run ID and sequence number. It returns false, when the
+// record is already in the current version.
+func MigrateRecord(b []byte, run string, seq uint64) (migrated []byte, upgraded bool, err error) {
+	var m map[string]interface{}
+	if m, err = unmarshalRecordMap(b); err != nil {
+		return
+	}
+
+	if v, ok := m["version"].(json.Number); ok {
+		var version int64
+		if version, err = v.Int64(); err != nil {
+			err = fmt.Errorf("invalid version, '%v'", v)
+			return
+		}
+		if int(version) >= RecordVersion {
+			return b, false, nil
+		}
+	}
+
+	if err = UpgradeRecordV0(m); err != nil {
+		return
+	}
+	m["run"] = run
+	m["seq"] = seq
+
+	if migrated, err = json.Marshal(m); err != nil {
+		return
+	}
+
+	return migrated, true, nil
+}
@@ -0,0 +1,123 @@
+package hotbody
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrateRecord(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		upgraded bool
+		expected map[string]interface{}
+		err      bool
+	}{
+		{
+			name:     "v0 payment",
+			line:     `{"type": "payment", "time": "2018-11-04T16:36:05.820744000", "elapsed": "0.5298993919", "transaction": "tx61", "error": null}`,
+			upgraded: true,
+			expected: map[string]interface{}{
+				"type":        "payment",
+				"time":        "2018-11-04T16:36:05.820744Z",
+				"elapsed":     "529899391",
+				"transaction": "tx61",
+				"version":     "1",
+				"run":         "run-0",
+				"seq":         "3",
+			},
+		},
+		{
+			name:     "v0 timings",
+			line:     `{"type": "payment", "time": "2018-11-04T16:36:05.000000000", "elapsed": "1", "timings": {"confirmed": "2018-11-04T16:36:04.100000000"}}`,
+			upgraded: true,
+			expected: map[string]interface{}{
+				"time":    "2018-11-04T16:36:05Z",
+				"elapsed": "1000000000",
+				"version": "1",
+			},
+		},
+		{
+			name:     "v1 is not changed",
+			line:     `{"type": "payment", "time": "2018-11-04T16:36:05.820744Z", "elapsed": 529899391, "version": 1, "run": "run-1", "seq": 7}`,
+			upgraded: false,
+			expected: map[string]interface{}{
+				"elapsed": "529899391",
+				"run":     "run-1",
+				"seq":     "7",
+			},
+		},
+		{name: "invalid elapsed", line: `{"type": "payment", "elapsed": "0.x"}`, err: true},
+		{name: "invalid time", line: `{"type": "payment", "time": "yesterday"}`, err: true},
+		{name: "broken json", line: `{"type": `, err: true},
+	}
+
+	for _, c := range cases {
+		migrated, upgraded, err := MigrateRecord([]byte(c.line), "run-0", 3)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected error", c.name)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%s: unexpected error; %v", c.name, err)
+			continue
+		}
+
+		if upgraded != c.upgraded {
+			t.Errorf("%s: expected upgraded=%v, but %v", c.name, c.upgraded, upgraded)
+		}
+
+		m, err := unmarshalRecordMap(migrated)
+		if err != nil {
+			t.Errorf("%s: failed to unmarshal migrated; %v", c.name, err)
+			continue
+		}
+		for key, expected := range c.expected {
+			if got := jsonString(m[key]); got != expected {
+				t.Errorf("%s: %s expected '%v', but '%v'", c.name, key, expected, got)
+			}
+		}
+
+		if !upgraded {
+			continue
+		}
+
+		// NOTE the migrated record is in the current version, so it is not
+		// upgraded again
+		again, upgradedAgain, err := MigrateRecord(migrated, "run-9", 9)
+		if err != nil || upgradedAgain || string(again) != string(migrated) {
+			t.Errorf("%s: migrated record is changed again; %s; %v", c.name, again, err)
+		}
+	}
+}
+
+func TestUpgradeRecordV0Timings(t *testing.T) {
+	m, err := unmarshalRecordMap([]byte(`{"elapsed": "0.1", "timings": {"sign-start": "2018-11-04T16:36:04.000000001", "confirmed": ""}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = UpgradeRecordV0(m); err != nil {
+		t.Fatal(err)
+	}
+
+	timings := m["timings"].(map[string]interface{})
+	if s := timings["sign-start"]; s != "2018-11-04T16:36:04.000000001Z" {
+		t.Errorf("sign-start: expected RFC3339Nano, but '%v'", s)
+	}
+	if s := timings["confirmed"]; s != "" {
+		t.Errorf("confirmed: expected empty, but '%v'", s)
+	}
+	if e := m["elapsed"]; e != int64(100000000) {
+		t.Errorf("elapsed: expected 100000000, but '%v'", e)
+	}
+}
+
+func jsonString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+
+	return string(b)
+}
@@ -0,0 +1,70 @@
+package hotbody
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/keypair"
+)
+
+// NewSeed returns the new seed for the running without seed.
+func NewSeed() int64 {
+	for {
+		if seed := time.Now().UnixNano(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// SeededRand is the random generator, which is safe for the concurrent use.
+// The testing accounts have their own SeededRand, so they do not wait for
+// each other.
+type SeededRand struct {
+	sync.Mutex
+	r *rand.Rand
+}
+
+func NewSeededRand(seed int64) *SeededRand {
+	return &SeededRand{r: rand.New(rand.NewSource(seed))}
+}
+
+func (s *SeededRand) Intn(n int) int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.r.Intn(n)
+}
+
+func (s *SeededRand) Perm(n int) []int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.r.Perm(n)
+}
+
+func hashSeed(seed int64, items ...interface{}) [sha256.Size]byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(seed))
+	for _, i := range items {
+		b = append(b, []byte(fmt.Sprintf(":%v", i))...)
+	}
+
+	return sha256.Sum256(b)
+}
+
+// DeriveKeypair derives the keypair of the testing account from the seed, the
+// address of init account and the index of account, so the same seed makes
+// the same accounts.
+func DeriveKeypair(seed int64, initAccount string, index int) (*keypair.Full, error) {
+	return keypair.FromRawSeed(hashSeed(seed, initAccount, index))
+}
+
+// DeriveSeed derives the seed of SeededRand for the address.
+func DeriveSeed(seed int64, address string) int64 {
+	h := hashSeed(seed, address)
+	return int64(binary.BigEndian.Uint64(h[:8]))
+}
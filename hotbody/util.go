@@ -1,19 +1,43 @@
 package hotbody
 
 import (
-	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func ElapsedTime(s time.Time) string {
-	return fmt.Sprintf("%.10f", float64(time.Now().Sub(s).Nanoseconds())/float64(1000000000))
+// ElapsedTime returns the nanoseconds since s.
+func ElapsedTime(s time.Time) int64 {
+	return time.Since(s).Nanoseconds()
 }
 
+// ParseRecordElapsedTime parses the elapsed time string of v0 record, the
+// seconds with 10 decimals, into nanoseconds without the loss of floating
+// point.
 func ParseRecordElapsedTime(s string) (int64, error) {
-	return strconv.ParseInt(strings.Replace(s, ".", "", 1), 10, 64)
+	var fraction string
+	p := strings.SplitN(s, ".", 2)
+	if len(p) > 1 {
+		fraction = p[1]
+	}
+
+	seconds, err := strconv.ParseInt(p[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(fraction) > 9 {
+		fraction = fraction[:9]
+	} else {
+		fraction += strings.Repeat("0", 9-len(fraction))
+	}
+
+	nanoseconds, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return seconds*int64(time.Second) + nanoseconds, nil
 }
 
 /*
@@ -41,73 +65,52 @@ func ParseRecordElapsedTime(s string) (int64, error) {
     "URL": "http://127.0.0.1:12345/api/v1/accounts/GAUSKC4GYKVNXSTVGZSZ6R3NEFNM7ZCEL5RIZXNOJ3Z2PQE67XNCQRCO"
 }
 */
-func ParseRecordErrorHTTPProblem(e map[string]interface{}) RecordErrorType {
-	switch e["type"].(string) {
-	case "https://boscoin.io/sebak/error/134":
-		return RecordErrorTxDoesNotExist
-	case "https://boscoin.io/sebak/error/139":
-		return RecordErrorSameSourceFound
+func parseRecordErrorNetError(e map[string]interface{}) ErrorClass {
+	m, found := e["Err"].(map[string]interface{})
+	if !found {
+		return ErrorClassNetwork
 	}
 
-	return RecordErrorUnknown
-}
+	if _, found = m["Syscall"]; !found {
+		return parseRecordErrorNetError(m)
+	}
 
-func ParseRecordErrorNetError(e map[string]interface{}) RecordErrorType {
-	var found bool
-	var m map[string]interface{}
-	if m, found = e["Err"].(map[string]interface{}); found {
-		if _, found = m["Syscall"]; found {
-			var code interface{}
-			if code, found = m["Err"]; !found {
-				return RecordErrorUnknown
-			} else if code.(float64) == float64(54) {
-				return RecordErrorECONNRESET
-			}
-		}
+	// NOTE the errno was serialized as the number of the platform, where
+	// the record was written.
+	code, _ := m["Err"].(float64)
+	switch code {
+	case 54, 104: // NOTE ECONNRESET of macOS and linux
+		return ErrorClassReset
+	case 61, 111: // NOTE ECONNREFUSED of macOS and linux
+		return ErrorClassRefused
+	case 60, 110: // NOTE ETIMEDOUT of macOS and linux
+		return ErrorClassTimeout
 	}
 
-	return RecordErrorNetworkError
+	return ErrorClassNetwork
 }
 
-func ParseRecordError(e map[string]interface{}) RecordErrorType {
-	var found bool
-	var m map[string]interface{}
-
-	{
-		var v interface{}
-		if v, found = e["code"]; found {
-			/*
-				code := v.(float64)
-				if code != 163 {
-					return RecordErrorUnknown
-				}
-			*/
-
-			if v, found = e["data"]; !found {
-				return RecordErrorUnknown
-			}
-
-			b := v.(map[string]interface{})["body"]
-			if b == nil {
-				return RecordErrorType(fmt.Sprintf("sebak-error-%d", int(e["code"].(float64))))
-			}
-
-			if err := json.Unmarshal([]byte(b.(string)), &m); err != nil {
-				return RecordErrorUnknown
-			}
-			if _, found := m["type"]; !found {
-				return ParseRecordError(m)
-			}
-
-			return ParseRecordErrorHTTPProblem(m)
-		}
+// ParseRecordError classifies the serialized error of the old record, which
+// does not have `error-class`.
+func ParseRecordError(e map[string]interface{}) (c RecordErrorClass) {
+	c.Class = ErrorClassUnknown
+	if len(e) < 1 {
+		return
 	}
 
-	{
-		if m, found = e["Err"].(map[string]interface{}); found {
-			return ParseRecordErrorNetError(m)
+	if _, found := e["code"]; found {
+		data, ok := e["data"].(map[string]interface{})
+		if !ok {
+			return
 		}
+		classifyHTTPProblem(data, &c)
+
+		return
+	}
+
+	if _, found := e["Err"]; found {
+		c.Class = parseRecordErrorNetError(e)
 	}
 
-	return RecordErrorUnknown
+	return
 }
@@ -0,0 +1,40 @@
+package hotbody
+
+import (
+	"testing"
+)
+
+func TestParseRecordElapsedTime(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected int64
+		err      bool
+	}{
+		{s: "0", expected: 0},
+		{s: "12", expected: 12000000000},
+		{s: "0.5", expected: 500000000},
+		{s: "0.000000001", expected: 1},
+		{s: "1.000000001", expected: 1000000001},
+		{s: "0.123456789", expected: 123456789},
+		// NOTE v0 has 10 decimals; under nanosecond is truncated
+		{s: "0.5298993919", expected: 529899391},
+		{s: "1.9999999999", expected: 1999999999},
+		{s: "0.0000000009", expected: 0},
+		{s: "3.", expected: 3000000000},
+		{s: "", err: true},
+		{s: "a.5", err: true},
+		{s: "1.5x", err: true},
+	}
+
+	for _, c := range cases {
+		got, err := ParseRecordElapsedTime(c.s)
+		switch {
+		case c.err && err == nil:
+			t.Errorf("'%s': expected error, but %d", c.s, got)
+		case !c.err && err != nil:
+			t.Errorf("'%s': unexpected error; %v", c.s, err)
+		case got != c.expected:
+			t.Errorf("'%s': expected %d, but %d", c.s, c.expected, got)
+		}
+	}
+}